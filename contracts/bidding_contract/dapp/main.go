@@ -4,23 +4,36 @@ import (
 	"fmt"
 	"log"
 
-	bidContractHost "bidding-contract/host"
-
 	wasmbridge "github.com/rubixchain/rubix-wasm/go-wasm-bridge"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/state"
 )
 
 const BIDDING_CONTRACT_WASM = "../../../artifacts/bidding_contract.wasm"
 
+// The contract state is kept in state/bidding_contract.json
+const (
+	BIDDING_CONTRACT_ID = "bidding_contract"
+	STATE_DIR           = "state"
+)
+
 func main() {
-	// Create host function registry and register GetSomeBid and SaveSomeBid
-	// host functions
-	hostRegistry := wasmbridge.NewHostFunctionRegistry()
+	stateStore, err := state.NewFileStore(STATE_DIR)
+	if err != nil {
+		log.Fatalf("Failed to open state store: %v\n", err)
+		return
+	}
 
-	hostRegistry.Register(bidContractHost.NewGetBid())
-	hostRegistry.Register(bidContractHost.NewSaveBid())
+	// The built-in state_get and state_set host functions are backed by
+	// the state store
+	hostRegistry := wasmbridge.NewHostFunctionRegistry()
 
 	// Initialize the WASM module
-	wasmModule, err := wasmbridge.NewWasmModule(BIDDING_CONTRACT_WASM, hostRegistry)
+	wasmModule, err := wasmbridge.NewWasmModule(
+		BIDDING_CONTRACT_WASM,
+		hostRegistry,
+		wasmbridge.WithStateStore(stateStore),
+		wasmbridge.WithContractID(BIDDING_CONTRACT_ID),
+	)
 	if err != nil {
 		log.Fatalf("Failed to initialize WASM module: %v\n", err)
 		return
@@ -4,4 +4,9 @@ go 1.22.4
 
 require github.com/rubixchain/rubix-wasm/go-wasm-bridge v0.1.1
 
-require github.com/bytecodealliance/wasmtime-go v1.0.0 // indirect
+require (
+	github.com/bytecodealliance/wasmtime-go v1.0.0 // indirect
+	github.com/gorilla/websocket v1.5.3 // indirect
+)
+
+replace github.com/rubixchain/rubix-wasm/go-wasm-bridge => ../../../go-wasm-bridge
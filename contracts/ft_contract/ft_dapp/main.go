@@ -5,6 +5,7 @@ import (
 	"log"
 
 	wasmbridge "github.com/rubixchain/rubix-wasm/go-wasm-bridge"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/signer"
 )
 
 const FT_CONTRACT_WASM = "../../../artifacts/ft_contract.wasm"
@@ -60,6 +61,8 @@ func main() {
 		hostFnRegistry,
 		wasmbridge.WithRubixNodeAddress(nodeAddress),
 		wasmbridge.WithQuorumType(quorumType),
+		// Sign the token transactions with the DID password in RUBIX_PASSWORD
+		wasmbridge.WithSigner(signer.NewEnvSigner("RUBIX_PASSWORD")),
 	)
 	if err != nil {
 		log.Fatalf("Failed to initialize WASM module: %v", err)
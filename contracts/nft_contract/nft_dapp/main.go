@@ -5,6 +5,7 @@ import (
 	"log"
 
 	wasmbridge "github.com/rubixchain/rubix-wasm/go-wasm-bridge"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/signer"
 )
 
 const NFT_CONTRACT_WASM = "../../../artifacts/nft_contract.wasm"
@@ -65,6 +66,8 @@ func main() {
 		hostFnRegistry,
 		wasmbridge.WithRubixNodeAddress(nodeAddress),
 		wasmbridge.WithQuorumType(quorumType),
+		// Sign the token transactions with the DID password in RUBIX_PASSWORD
+		wasmbridge.WithSigner(signer.NewEnvSigner("RUBIX_PASSWORD")),
 	)
 	if err != nil {
 		log.Fatalf("Failed to initialize WASM module: %v", err)
@@ -0,0 +1,161 @@
+package wasmbridge
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bytecodealliance/wasmtime-go"
+)
+
+// contractFnSuffix is appended by the contract_fn macro of packages/derive
+// to the name of the exported wrapper function
+const contractFnSuffix = "_"
+
+// Signatures of the exports generated by packages/derive and packages/std
+var (
+	// fn <name>_(input_ptr, input_len, output_ptr_ptr, output_len_ptr) -> i32
+	contractFnSignature = abiSignature{
+		params:  []wasmtime.ValKind{wasmtime.KindI32, wasmtime.KindI32, wasmtime.KindI32, wasmtime.KindI32},
+		results: []wasmtime.ValKind{wasmtime.KindI32},
+	}
+	// fn alloc(size) -> ptr
+	allocSignature = abiSignature{
+		params:  []wasmtime.ValKind{wasmtime.KindI32},
+		results: []wasmtime.ValKind{wasmtime.KindI32},
+	}
+	// fn dealloc(ptr, size)
+	deallocSignature = abiSignature{
+		params:  []wasmtime.ValKind{wasmtime.KindI32, wasmtime.KindI32},
+		results: []wasmtime.ValKind{},
+	}
+)
+
+type abiSignature struct {
+	params  []wasmtime.ValKind
+	results []wasmtime.ValKind
+}
+
+func (s abiSignature) matches(funcType *wasmtime.FuncType) bool {
+	return valKindsEqual(s.params, valKinds(funcType.Params())) &&
+		valKindsEqual(s.results, valKinds(funcType.Results()))
+}
+
+func (s abiSignature) String() string {
+	return formatSignature(s.params, s.results)
+}
+
+// ABIError is returned when the exports of a contract do not match the
+// ABI generated by packages/derive and packages/std
+type ABIError struct {
+	Problems []string
+}
+
+func (e *ABIError) Error() string {
+	return fmt.Sprintf("contract does not match the rubix-wasm ABI: %v", strings.Join(e.Problems, "; "))
+}
+
+// validateABI checks the exports of the module against the contract ABI
+// and returns the names of the exported contract functions
+func validateABI(module *wasmtime.Module) ([]string, error) {
+	var problems []string
+	var functions []string
+	found := map[string]bool{}
+
+	for _, export := range module.Exports() {
+		name := export.Name()
+		funcType := export.Type().FuncType()
+
+		switch name {
+		case "memory":
+			found[name] = true
+			if export.Type().MemoryType() == nil {
+				problems = append(problems, "export memory is not a memory")
+			}
+		case "alloc":
+			found[name] = true
+			problems = append(problems, checkSignature(name, funcType, allocSignature)...)
+		case "dealloc":
+			found[name] = true
+			problems = append(problems, checkSignature(name, funcType, deallocSignature)...)
+		default:
+			if funcType == nil || len(name) <= len(contractFnSuffix) || !strings.HasSuffix(name, contractFnSuffix) {
+				continue
+			}
+			if contractFnSignature.matches(funcType) {
+				functions = append(functions, strings.TrimSuffix(name, contractFnSuffix))
+			} else {
+				problems = append(problems, checkSignature(name, funcType, contractFnSignature)...)
+			}
+		}
+	}
+
+	for _, required := range []string{"memory", "alloc", "dealloc"} {
+		if !found[required] {
+			problems = append(problems, fmt.Sprintf("export %v is missing", required))
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, &ABIError{Problems: problems}
+	}
+
+	sort.Strings(functions)
+	return functions, nil
+}
+
+func checkSignature(name string, funcType *wasmtime.FuncType, expected abiSignature) []string {
+	if funcType == nil {
+		return []string{fmt.Sprintf("export %v is not a function", name)}
+	}
+	if !expected.matches(funcType) {
+		return []string{fmt.Sprintf(
+			"export %v has signature %v, expected %v",
+			name, formatFuncType(funcType), expected,
+		)}
+	}
+	return nil
+}
+
+func valKinds(valTypes []*wasmtime.ValType) []wasmtime.ValKind {
+	kinds := make([]wasmtime.ValKind, len(valTypes))
+	for i, valType := range valTypes {
+		kinds[i] = valType.Kind()
+	}
+	return kinds
+}
+
+func valKindsEqual(a, b []wasmtime.ValKind) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// formatFuncType renders a function type as "(i32, i32) -> i32"
+func formatFuncType(funcType *wasmtime.FuncType) string {
+	return formatSignature(valKinds(funcType.Params()), valKinds(funcType.Results()))
+}
+
+func formatSignature(params, results []wasmtime.ValKind) string {
+	format := func(kinds []wasmtime.ValKind) string {
+		names := make([]string, len(kinds))
+		for i, kind := range kinds {
+			names[i] = kind.String()
+		}
+		return strings.Join(names, ", ")
+	}
+
+	return fmt.Sprintf("(%v) -> (%v)", format(params), format(results))
+}
+
+// Functions returns the names of the contract functions exported by the
+// contract, i.e. the functions annotated with contract_fn
+func (w *WasmModule) Functions() []string {
+	return append([]string(nil), w.functions...)
+}
@@ -0,0 +1,42 @@
+package wasmbridge
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFunctions(t *testing.T) {
+	module := newTestModule(t, testContract("", okFunc, echoFunc, failFunc))
+
+	functions := module.Functions()
+	expected := []string{"echo", "fail", "ok"}
+	if strings.Join(functions, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected functions %v, got %v", expected, functions)
+	}
+}
+
+func TestABIValidation(t *testing.T) {
+	for name, contract := range map[string]string{
+		"missing alloc": `(module
+  (memory (export "memory") 1)
+  (func (export "dealloc") (param i32 i32)))`,
+		"missing memory": `(module
+  (func (export "alloc") (param i32) (result i32) i32.const 0)
+  (func (export "dealloc") (param i32 i32)))`,
+		"bad alloc signature": `(module
+  (memory (export "memory") 1)
+  (func (export "alloc") (param i64) (result i32) i32.const 0)
+  (func (export "dealloc") (param i32 i32)))`,
+		"bad contract function signature": string(testContract("",
+			`(func (export "bad_") (param i32) (result i32) i32.const 0)`)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewWasmModule(writeTestContract(t, []byte(contract)), NewHostFunctionRegistry())
+			var abiErr *ABIError
+			if !errors.As(err, &abiErr) || len(abiErr.Problems) == 0 {
+				t.Fatalf("expected an ABIError, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package wasmbridge
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sync"
+
+	"github.com/bytecodealliance/wasmtime-go"
+)
+
+// wasmtimeModulePath is the module path of the Wasmtime bindings, whose
+// version is part of engineFingerprint
+const wasmtimeModulePath = "github.com/bytecodealliance/wasmtime-go"
+
+// engineFingerprint identifies the engine configuration a compiled module
+// was produced with: the version of the Wasmtime bindings, the settings of
+// newEngine and the platform. Entries with another fingerprint are
+// recompiled. Wasmtime checks compatibility on deserialization as well, and
+// entries it rejects are recompiled too.
+var engineFingerprint = func() string {
+	fingerprint := wasmtimeModulePath + "@" + wasmtimeVersion()
+	for _, setting := range engineSettings {
+		fingerprint += ";" + setting.name
+	}
+	return fingerprint + ";" + runtime.GOOS + "/" + runtime.GOARCH
+}()
+
+// wasmtimeVersion returns the version of the Wasmtime bindings the binary
+// was built with
+func wasmtimeVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != wasmtimeModulePath {
+			continue
+		}
+		if dep.Replace != nil {
+			return dep.Replace.Path + "@" + dep.Replace.Version
+		}
+		return dep.Version
+	}
+	return "unknown"
+}
+
+// cacheEntryMagic prefixes every cache entry on disk
+const cacheEntryMagic = "RUBIXWASM-MODULE\n"
+
+// ModuleCache compiles contracts once on a shared engine and persists the
+// compiled artifacts to a cache directory, keyed by the SHA-256 hash of
+// the WASM content. Modules loaded through the same cache share one engine,
+// so cancelling a call interrupts their other context-aware calls as
+// described for WasmPool.
+type ModuleCache struct {
+	engine *wasmtime.Engine
+	dir    string
+
+	mu      sync.Mutex
+	modules map[string]*wasmtime.Module
+}
+
+// NewModuleCache returns a module cache backed by cacheDir, which is
+// created if it does not exist
+func NewModuleCache(cacheDir string) (*ModuleCache, error) {
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create module cache directory: %w", err)
+	}
+
+	return &ModuleCache{
+		engine:  newEngine(),
+		dir:     cacheDir,
+		modules: make(map[string]*wasmtime.Module),
+	}, nil
+}
+
+// WithModuleCache compiles the contract through the provided cache
+func WithModuleCache(cache *ModuleCache) WasmModuleOption {
+	return func(w *WasmModule) {
+		w.moduleCache = cache
+	}
+}
+
+// Module returns the compiled module for the WASM binary. It is looked up
+// in memory, then on disk, and compiled and persisted as a last resort.
+func (c *ModuleCache) Module(wasmBytes []byte) (*wasmtime.Module, error) {
+	hash := sha256.Sum256(wasmBytes)
+	key := hex.EncodeToString(hash[:])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if module, ok := c.modules[key]; ok {
+		return module, nil
+	}
+
+	module, err := c.load(key)
+	if err != nil {
+		module, err = wasmtime.NewModule(c.engine, wasmBytes)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := c.store(key, module); err != nil {
+			return nil, err
+		}
+	}
+
+	c.modules[key] = module
+	return module, nil
+}
+
+func (c *ModuleCache) entryPath(key string) string {
+	return filepath.Join(c.dir, key+".cwasm")
+}
+
+// load deserializes a cache entry, rejecting entries which were produced
+// by an incompatible engine configuration
+func (c *ModuleCache) load(key string) (*wasmtime.Module, error) {
+	entry, err := os.ReadFile(c.entryPath(key))
+	if err != nil {
+		return nil, err
+	}
+
+	header := cacheEntryMagic + engineFingerprint + "\n"
+	if !bytes.HasPrefix(entry, []byte(header)) {
+		return nil, fmt.Errorf("cache entry %s was produced by an incompatible engine", key)
+	}
+
+	return wasmtime.NewModuleDeserialize(c.engine, entry[len(header):])
+}
+
+// store serializes the module to its cache entry
+func (c *ModuleCache) store(key string, module *wasmtime.Module) error {
+	serialized, err := module.Serialize()
+	if err != nil {
+		return fmt.Errorf("failed to serialize module: %w", err)
+	}
+
+	header := cacheEntryMagic + engineFingerprint + "\n"
+	entry := append([]byte(header), serialized...)
+
+	// Write to a temporary file first so readers never see a partial entry
+	tmpFile, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache entry: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(entry); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	return os.Rename(tmpFile.Name(), c.entryPath(key))
+}
@@ -0,0 +1,138 @@
+package wasmbridge
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cacheEntries returns the paths of the entries in a module cache
+func cacheEntries(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := filepath.Glob(filepath.Join(dir, "*.cwasm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func TestModuleCachePersistsModules(t *testing.T) {
+	dir := t.TempDir()
+	contract := testContract("", okFunc)
+
+	cache, err := NewModuleCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newTestModule(t, contract, WithModuleCache(cache))
+
+	entries := cacheEntries(t, dir)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 cache entry, got %v", entries)
+	}
+
+	// A new cache on the same directory loads the entry from disk
+	reopened, err := NewModuleCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	module := newTestModule(t, contract, WithModuleCache(reopened))
+	if output, err := module.CallFunction(`{"ok": {}}`); err != nil || output != "ok" {
+		t.Fatalf("cached module call returned %q, %v", output, err)
+	}
+}
+
+func TestModuleCacheSharesEngine(t *testing.T) {
+	cache, err := NewModuleCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := newTestModule(t, testContract("", okFunc), WithModuleCache(cache))
+	second := newTestModule(t, testContract("", okFunc, spinFunc), WithModuleCache(cache))
+
+	if first.engine != cache.engine || second.engine != cache.engine {
+		t.Error("expected modules loaded through the cache to use its engine")
+	}
+}
+
+func TestModuleCacheRecompilesIncompatibleEntries(t *testing.T) {
+	contract := testContract("", okFunc)
+
+	for name, entry := range map[string][]byte{
+		"stale fingerprint": []byte(cacheEntryMagic + "wasmtime-go@v0.0.0\n" + "artifact"),
+		"corrupt artifact":  []byte(cacheEntryMagic + engineFingerprint + "\n" + "artifact"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			cache, err := NewModuleCache(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			newTestModule(t, contract, WithModuleCache(cache))
+			entryPath := cacheEntries(t, dir)[0]
+			if err := os.WriteFile(entryPath, entry, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			reopened, err := NewModuleCache(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			module := newTestModule(t, contract, WithModuleCache(reopened))
+			if _, err := module.CallFunction(`{"ok": {}}`); err != nil {
+				t.Fatalf("recompiled module call failed: %v", err)
+			}
+
+			rewritten, err := os.ReadFile(entryPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(rewritten, entry) {
+				t.Error("incompatible cache entry was not replaced")
+			}
+		})
+	}
+}
+
+func TestEngineFingerprintHasSettings(t *testing.T) {
+	if !strings.HasPrefix(engineFingerprint, wasmtimeModulePath+"@v") {
+		t.Errorf("fingerprint %q lacks the version of %v", engineFingerprint, wasmtimeModulePath)
+	}
+	for _, setting := range engineSettings {
+		if !strings.Contains(engineFingerprint, ";"+setting.name+";") {
+			t.Errorf("fingerprint %q lacks the engine setting %v", engineFingerprint, setting.name)
+		}
+	}
+}
+
+func TestCachedModuleCancellationSparesOtherModules(t *testing.T) {
+	cache, err := NewModuleCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := testContract("", spinFunc, slowFunc)
+	spinning := newTestModule(t, contract, WithModuleCache(cache))
+	slow := newTestModule(t, contract, WithModuleCache(cache))
+
+	longCtx, cancelLong := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelLong()
+	slowDone := make(chan error, 1)
+	go func() {
+		_, err := slow.CallFunctionContext(longCtx, `{"slow": {}}`)
+		slowDone <- err
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := spinning.CallFunctionContext(ctx, `{"spin": {}}`); err == nil {
+		t.Fatal("expected the spinning call to be interrupted")
+	}
+	if err := <-slowDone; err != nil {
+		t.Fatalf("call of the other cached module failed: %v", err)
+	}
+}
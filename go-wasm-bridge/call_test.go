@@ -0,0 +1,93 @@
+package wasmbridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestCallPassesRawPayload(t *testing.T) {
+	module := newTestModule(t, testContract("", echoFunc))
+
+	// The contract receives the payload as is and echoes it as its output
+	result, err := module.Call("echo", json.RawMessage(`"hello"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "hello" {
+		t.Errorf("expected output hello, got %q", result.Output)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	module := newTestModule(t, testContract("", echoFunc))
+
+	result, err := module.Execute(context.Background(), `{"echo": "hello"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "hello" {
+		t.Errorf("expected output hello, got %q", result.Output)
+	}
+
+	output, err := module.CallFunction(`{"echo": "world"}`)
+	if err != nil || output != "world" {
+		t.Errorf("CallFunction returned %q, %v", output, err)
+	}
+}
+
+func TestCallInputErrors(t *testing.T) {
+	module := newTestModule(t, testContract("", echoFunc))
+
+	var inputErr *InputDecodeError
+	for _, args := range []string{`not json`, `{}`, `{"echo": "a", "ok": "b"}`} {
+		if _, err := module.Execute(context.Background(), args); !errors.As(err, &inputErr) {
+			t.Errorf("%s: expected an InputDecodeError, got %v", args, err)
+		}
+	}
+	if _, err := module.Call("echo", json.RawMessage(`{`)); !errors.As(err, &inputErr) {
+		t.Errorf("expected an InputDecodeError for an invalid payload, got %v", err)
+	}
+
+	if _, err := module.Call("missing", json.RawMessage(`{}`)); !errors.Is(err, ErrFunctionNotFound) {
+		t.Errorf("expected ErrFunctionNotFound for a missing function, got %v", err)
+	}
+}
+
+func TestCallOutputErrors(t *testing.T) {
+	module := newTestModule(t, testContract("", echoFunc))
+
+	// The contract output must be a JSON string
+	var outputErr *OutputDecodeError
+	if _, err := module.Call("echo", json.RawMessage(`{"a": 1}`)); !errors.As(err, &outputErr) {
+		t.Errorf("expected an OutputDecodeError, got %v", err)
+	}
+}
+
+func TestCallTyped(t *testing.T) {
+	module := newTestModule(t, testContract("", echoFunc))
+
+	type bid struct {
+		Amount int `json:"amount"`
+	}
+
+	// echo returns the JSON encoded request as a string, which is decoded
+	// into the response
+	encoded, err := json.Marshal(`{"amount":42}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, result, err := CallTyped[json.RawMessage, bid](context.Background(), module, "echo", encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Amount != 42 || result.Output != `{"amount":42}` {
+		t.Errorf("unexpected response %+v, output %q", resp, result.Output)
+	}
+
+	output, _, err := CallTyped[string, string](context.Background(), module, "echo", "plain")
+	if err != nil || output != "plain" {
+		t.Errorf("expected string output plain, got %q, %v", output, err)
+	}
+}
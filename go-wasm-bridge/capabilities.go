@@ -0,0 +1,128 @@
+package wasmbridge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/bytecodealliance/wasmtime-go"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/utils"
+)
+
+// ErrPermissionDenied is matched by errors returned when a contract calls
+// a host function it has not been granted the capability for
+var ErrPermissionDenied = errors.New("permission denied")
+
+// CodePermissionDenied is the guest error code of a host function call
+// denied by the capability policy, see WithGuestErrors
+const CodePermissionDenied int32 = 403
+
+// PermissionError describes a host function call denied by the
+// capability policy of the module
+type PermissionError struct {
+	Function   string
+	Capability string
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("%v: host function %v requires capability %q", ErrPermissionDenied, e.Function, e.Capability)
+}
+
+func (e *PermissionError) Is(target error) bool {
+	return target == ErrPermissionDenied
+}
+
+// ContractManifest declares what a contract may do. Operators review the
+// manifest instead of the contract binary.
+type ContractManifest struct {
+	Name         string   `json:"name"`
+	Capabilities []string `json:"capabilities"`
+}
+
+// LoadContractManifest reads a JSON contract manifest, such as
+//
+//	{"name": "bidding_contract", "capabilities": ["http", "ft.*"]}
+func LoadContractManifest(manifestPath string) (*ContractManifest, error) {
+	manifestBytes, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest ContractManifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse contract manifest %v: %w", manifestPath, err)
+	}
+	for _, capability := range manifest.Capabilities {
+		if _, err := path.Match(capability, ""); err != nil {
+			return nil, fmt.Errorf("invalid capability %q in contract manifest %v", capability, manifestPath)
+		}
+	}
+
+	return &manifest, nil
+}
+
+// WithCapabilities restricts the contract to the host functions whose
+// capability is granted, see host.CapabilityOf. A capability may use
+// wildcards, as in "nft.*" or "*". Calls to other host functions fail with
+// a PermissionError, or with guest error CodePermissionDenied if the module
+// hands host errors back to the contract. Without this option every host
+// function is allowed.
+func WithCapabilities(capabilities ...string) WasmModuleOption {
+	return func(w *WasmModule) {
+		w.capabilities = append([]string{}, capabilities...)
+	}
+}
+
+// WithCapabilityManifest grants the capabilities declared by manifest
+func WithCapabilityManifest(manifest *ContractManifest) WasmModuleOption {
+	return WithCapabilities(manifest.Capabilities...)
+}
+
+// Capabilities returns the capabilities granted to the contract, or nil if
+// every host function is allowed
+func (w *WasmModule) Capabilities() []string {
+	return w.capabilities
+}
+
+// allowed reports whether the capability policy grants hf
+func (w *WasmModule) allowed(hf host.HostFunction) bool {
+	if w.capabilities == nil {
+		return true
+	}
+
+	capability := host.CapabilityOf(hf)
+	for _, granted := range w.capabilities {
+		if ok, _ := path.Match(granted, capability); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// deniedHostCall replaces the callback of a host function the contract
+// lacks the capability for. With guest errors, host functions of the
+// standard (input_ptr, input_len, resp_ptr_ptr, resp_len_ptr) -> i32
+// signature hand the denial back to the contract with code
+// CodePermissionDenied; other host functions trap.
+func (w *WasmModule) deniedHostCall(hf host.HostFunction) host.HostFunctionCallBack {
+	permErr := &PermissionError{Function: qualifiedName(hf), Capability: host.CapabilityOf(hf)}
+	// The standard host function signature matches the contract functions
+	standard := contractFnSignature.matches(hf.FuncType())
+
+	return func(caller *wasmtime.Caller, args []wasmtime.Val) ([]wasmtime.Val, *wasmtime.Trap) {
+		if standard && w.wasmCtx.GuestErrors() {
+			if alloc := caller.GetExport("alloc"); alloc != nil && alloc.Func() != nil {
+				if _, outputArgs, err := utils.HostFunctionParamExtraction(args, true, true); err == nil {
+					w.wasmCtx.Log(fmt.Sprintf("%v: %v", permErr.Function, permErr))
+					return utils.HandleGuestError(caller, alloc.Func(), outputArgs, CodePermissionDenied, permErr.Error())
+				}
+			}
+		}
+
+		w.wasmCtx.SetHostError(permErr)
+		return utils.HandleError(permErr.Error())
+	}
+}
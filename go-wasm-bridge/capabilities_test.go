@@ -0,0 +1,72 @@
+package wasmbridge
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// emitContract emits the event it is called with
+var emitContract = testContract(hostImport("env", "emit_event"), hostCallFunc("emit_event"))
+
+func TestCapabilities(t *testing.T) {
+	for _, test := range []struct {
+		capabilities []string
+		allowed      bool
+	}{
+		{nil, true},
+		{[]string{"events"}, true},
+		{[]string{"ev*"}, true},
+		{[]string{"*"}, true},
+		{[]string{}, false},
+		{[]string{"http", "state.*"}, false},
+	} {
+		var opts []WasmModuleOption
+		if test.capabilities != nil {
+			opts = append(opts, WithCapabilities(test.capabilities...))
+		}
+		module := newTestModule(t, emitContract, opts...)
+
+		_, err := module.Call("emit_event", []byte(`{"name": "bid"}`))
+		if test.allowed && err != nil {
+			t.Errorf("%v: expected emit_event to be allowed, got %v", test.capabilities, err)
+		}
+		if !test.allowed {
+			var hostErr *HostFunctionError
+			var permErr *PermissionError
+			if !errors.Is(err, ErrPermissionDenied) || !errors.As(err, &hostErr) || !errors.As(err, &permErr) {
+				t.Errorf("%v: expected a permission denied HostFunctionError, got %v", test.capabilities, err)
+			} else if permErr.Capability != "events" || permErr.Function != "emit_event" {
+				t.Errorf("%v: unexpected PermissionError %+v", test.capabilities, permErr)
+			}
+		}
+	}
+}
+
+func TestLoadContractManifest(t *testing.T) {
+	dir := t.TempDir()
+	manifestPath := filepath.Join(dir, "manifest.json")
+
+	if err := os.WriteFile(manifestPath, []byte(`{"name": "bidding", "capabilities": ["events", "state.*"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := LoadContractManifest(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	module := newTestModule(t, emitContract, WithCapabilityManifest(manifest))
+	if len(module.Capabilities()) != 2 {
+		t.Errorf("unexpected capabilities %v", module.Capabilities())
+	}
+	if _, err := module.Call("emit_event", []byte(`{"name": "bid"}`)); err != nil {
+		t.Errorf("expected emit_event to be allowed, got %v", err)
+	}
+
+	if err := os.WriteFile(manifestPath, []byte(`{"capabilities": ["[events"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadContractManifest(manifestPath); err == nil {
+		t.Error("expected an invalid capability pattern to be rejected")
+	}
+}
@@ -2,14 +2,56 @@ package context
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/rubixclient"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/signer"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/state"
 )
 
 type WasmContext struct {
-	baseCtx context.Context
+	baseCtx *baseContext
+	records *callRecords
 	externalSocketConn *websocket.Conn
+
+	// guestErrors makes host functions hand errors back to the contract
+	// instead of trapping
+	guestErrors bool
+
+	// Contract state
+	contractID string
+	stateStore state.StateStore
+
+	// rubixClient calls the Rubix node on behalf of host functions
+	rubixClient *rubixclient.Client
+
+	// signer answers the signature requests of pending transactions
+	signer signer.Signer
+}
+
+// Event is a structured event emitted by a host function during a
+// contract call
+type Event struct {
+	Name string `json:"name"`
+	Data string `json:"data"`
+}
+
+// callRecords collects the logs, events and host function error of the
+// current contract call
+type callRecords struct {
+	mu      sync.Mutex
+	logs    []string
+	events  []Event
+	hostErr error
+}
+
+// baseContext guards the base context.Context, which is swapped on every
+// contract call while HTTP clients may still be watching the previous one
+type baseContext struct {
+	mu  sync.RWMutex
+	ctx context.Context
 }
 
 func (c *WasmContext) WithExternalSocketConn(conn *websocket.Conn) *WasmContext {
@@ -17,6 +59,152 @@ func (c *WasmContext) WithExternalSocketConn(conn *websocket.Conn) *WasmContext
 	return c
 }
 
+// Clone returns a shallow copy of the WasmContext, so that it can be
+// handed to another WASM instance
+func (c *WasmContext) Clone() *WasmContext {
+	clone := *c
+	clone.baseCtx = &baseContext{ctx: c.BaseContext()}
+	clone.records = &callRecords{}
+	return &clone
+}
+
+// WithBaseContext sets the context.Context that WasmContext delegates
+// deadlines, cancellation and values to
+func (c *WasmContext) WithBaseContext(ctx context.Context) *WasmContext {
+	if c.baseCtx == nil {
+		c.baseCtx = &baseContext{}
+	}
+	c.baseCtx.mu.Lock()
+	c.baseCtx.ctx = ctx
+	c.baseCtx.mu.Unlock()
+	return c
+}
+
+func (c WasmContext) BaseContext() context.Context {
+	if c.baseCtx == nil {
+		return context.Background()
+	}
+	c.baseCtx.mu.RLock()
+	defer c.baseCtx.mu.RUnlock()
+	return c.baseCtx.ctx
+}
+
+// WithGuestErrors sets whether host functions hand their errors back to
+// the contract as an error payload instead of trapping
+func (c *WasmContext) WithGuestErrors(enabled bool) *WasmContext {
+	c.guestErrors = enabled
+	return c
+}
+
+// GuestErrors reports whether host functions hand their errors back to
+// the contract instead of trapping
+func (c WasmContext) GuestErrors() bool {
+	return c.guestErrors
+}
+
+// WithContractID sets the ID under which the state of the contract is kept
+func (c *WasmContext) WithContractID(contractID string) *WasmContext {
+	c.contractID = contractID
+	return c
+}
+
+func (c WasmContext) ContractID() string {
+	return c.contractID
+}
+
+// WithStateStore sets the store backing the state host functions
+func (c *WasmContext) WithStateStore(store state.StateStore) *WasmContext {
+	c.stateStore = store
+	return c
+}
+
+// StateStore returns the store backing the state host functions, or nil if
+// the contract has no state
+func (c WasmContext) StateStore() state.StateStore {
+	return c.stateStore
+}
+
+// WithRubixClient sets the client host functions call the Rubix node with
+func (c *WasmContext) WithRubixClient(client *rubixclient.Client) *WasmContext {
+	c.rubixClient = client
+	return c
+}
+
+// RubixClient returns the client host functions call the Rubix node with,
+// or nil if none is set
+func (c WasmContext) RubixClient() *rubixclient.Client {
+	return c.rubixClient
+}
+
+// WithSigner sets the signer of the pending transactions host functions
+// start
+func (c *WasmContext) WithSigner(s signer.Signer) *WasmContext {
+	c.signer = s
+	return c
+}
+
+// Signer returns the signer of pending transactions, or nil if none is set
+func (c WasmContext) Signer() signer.Signer {
+	return c.signer
+}
+
+// Log records a log line for the current contract call
+func (c *WasmContext) Log(msg string) {
+	if c.records == nil {
+		c.records = &callRecords{}
+	}
+	c.records.mu.Lock()
+	c.records.logs = append(c.records.logs, msg)
+	c.records.mu.Unlock()
+}
+
+// EmitEvent records an event for the current contract call
+func (c *WasmContext) EmitEvent(name string, data string) {
+	if c.records == nil {
+		c.records = &callRecords{}
+	}
+	c.records.mu.Lock()
+	c.records.events = append(c.records.events, Event{Name: name, Data: data})
+	c.records.mu.Unlock()
+}
+
+// DrainRecords returns and clears the logs and events recorded so far
+func (c *WasmContext) DrainRecords() ([]string, []Event) {
+	if c.records == nil {
+		return nil, nil
+	}
+	c.records.mu.Lock()
+	defer c.records.mu.Unlock()
+
+	logs, events := c.records.logs, c.records.events
+	c.records.logs, c.records.events = nil, nil
+	return logs, events
+}
+
+// SetHostError records the error a host function fails with, so that it
+// is reported as the cause of the failed contract call
+func (c *WasmContext) SetHostError(err error) {
+	if c.records == nil {
+		c.records = &callRecords{}
+	}
+	c.records.mu.Lock()
+	c.records.hostErr = err
+	c.records.mu.Unlock()
+}
+
+// TakeHostError returns and clears the error recorded by SetHostError
+func (c *WasmContext) TakeHostError() error {
+	if c.records == nil {
+		return nil
+	}
+	c.records.mu.Lock()
+	defer c.records.mu.Unlock()
+
+	err := c.records.hostErr
+	c.records.hostErr = nil
+	return err
+}
+
 func (c WasmContext) ExternalSocketConn() *websocket.Conn {
 	if c.externalSocketConn == nil {
 		return nil
@@ -25,26 +213,27 @@ func (c WasmContext) ExternalSocketConn() *websocket.Conn {
 }
 
 func (c WasmContext) Deadline() (deadline time.Time, ok bool) {
-	return c.baseCtx.Deadline()
+	return c.BaseContext().Deadline()
 }
 
 func (c WasmContext) Done() <-chan struct{} {
-	return c.baseCtx.Done()
+	return c.BaseContext().Done()
 }
 
 func (c WasmContext) Err() error {
-	return c.baseCtx.Err()
+	return c.BaseContext().Err()
 }
 
 func (c WasmContext) Value(key interface{}) interface{} {
-	return c.baseCtx.Value(key)
+	return c.BaseContext().Value(key)
 }
 
 var _ context.Context = WasmContext{}
 
 func NewWasmContext() *WasmContext {
 	return &WasmContext{
-		baseCtx: context.Background(),
+		baseCtx: &baseContext{ctx: context.Background()},
+		records: &callRecords{},
 		externalSocketConn: nil,
 	}
 }
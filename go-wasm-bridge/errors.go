@@ -0,0 +1,178 @@
+package wasmbridge
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bytecodealliance/wasmtime-go"
+)
+
+// ContractError is returned when a contract function completes with a
+// non-zero return code. Message holds the error message of the contract.
+type ContractError struct {
+	Function string
+	Code     int32
+	Message  string
+}
+
+func (e *ContractError) Error() string {
+	return fmt.Sprintf("contract execution failed: %v", e.Message)
+}
+
+// TrapKind classifies the reason guest code trapped
+type TrapKind string
+
+const (
+	TrapUnknown                TrapKind = "unknown"
+	TrapOutOfGas               TrapKind = "out_of_gas"
+	TrapInterrupted            TrapKind = "interrupted"
+	TrapStackOverflow          TrapKind = "stack_overflow"
+	TrapMemoryOutOfBounds      TrapKind = "memory_out_of_bounds"
+	TrapHeapMisaligned         TrapKind = "heap_misaligned"
+	TrapTableOutOfBounds       TrapKind = "table_out_of_bounds"
+	TrapIndirectCallToNull     TrapKind = "indirect_call_to_null"
+	TrapBadSignature           TrapKind = "bad_signature"
+	TrapIntegerOverflow        TrapKind = "integer_overflow"
+	TrapIntegerDivisionByZero  TrapKind = "integer_division_by_zero"
+	TrapBadConversionToInteger TrapKind = "bad_conversion_to_integer"
+	TrapUnreachable            TrapKind = "unreachable"
+	TrapResourceLimit          TrapKind = "resource_limit"
+)
+
+var trapKinds = map[wasmtime.TrapCode]TrapKind{
+	wasmtime.StackOverflow:          TrapStackOverflow,
+	wasmtime.MemoryOutOfBounds:      TrapMemoryOutOfBounds,
+	wasmtime.HeapMisaligned:         TrapHeapMisaligned,
+	wasmtime.TableOutOfBounds:       TrapTableOutOfBounds,
+	wasmtime.IndirectCallToNull:     TrapIndirectCallToNull,
+	wasmtime.BadSignature:           TrapBadSignature,
+	wasmtime.IntegerOverflow:        TrapIntegerOverflow,
+	wasmtime.IntegerDivisionByZero:  TrapIntegerDivisionByZero,
+	wasmtime.BadConversionToInteger: TrapBadConversionToInteger,
+	wasmtime.UnreachableCodeReached: TrapUnreachable,
+	wasmtime.Interrupt:              TrapInterrupted,
+}
+
+// TrapError is returned when guest code traps. Err holds the underlying
+// cause, such as the context error of an interrupted call.
+type TrapError struct {
+	Function string
+	Kind     TrapKind
+	Message  string
+	Err      error
+}
+
+func (e *TrapError) Error() string {
+	switch e.Kind {
+	case TrapOutOfGas:
+		return fmt.Sprintf("%v: %v", ErrOutOfGas, e.Message)
+	case TrapResourceLimit:
+		return fmt.Sprintf("%v: %v", ErrResourceLimit, e.Message)
+	}
+	return fmt.Sprintf("contract trapped (%v): %v", e.Kind, e.Message)
+}
+
+func (e *TrapError) Unwrap() error {
+	return e.Err
+}
+
+// Is lets errors.Is(err, ErrOutOfGas) and errors.Is(err, ErrResourceLimit)
+// match out of gas and resource limit traps
+func (e *TrapError) Is(target error) bool {
+	return (target == ErrOutOfGas && e.Kind == TrapOutOfGas) ||
+		(target == ErrResourceLimit && e.Kind == TrapResourceLimit)
+}
+
+// HostFunctionError is returned when a host function invoked by the
+// contract fails. Err holds the error the host function failed with.
+type HostFunctionError struct {
+	Function string
+	Message  string
+	Err      error
+}
+
+func (e *HostFunctionError) Error() string {
+	return fmt.Sprintf("host function %v failed: %v", e.Function, e.Message)
+}
+
+func (e *HostFunctionError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	// ErrFunctionNotFound is matched by the error of a call of a function
+	// which the contract does not export
+	ErrFunctionNotFound = errors.New("function does not exist in the contract")
+
+	// ErrNotAFunction is matched by the error of a call of an export of
+	// the contract which is not a function
+	ErrNotAFunction = errors.New("export is not a function")
+)
+
+// FunctionLookupError is returned when the called function cannot be
+// found in the contract. Err is ErrFunctionNotFound or ErrNotAFunction.
+type FunctionLookupError struct {
+	Function string
+	Err      error
+}
+
+func (e *FunctionLookupError) Error() string {
+	if e.Err == ErrNotAFunction {
+		return fmt.Sprintf("export %v is not a function", e.Function)
+	}
+	return fmt.Sprintf("function %v does not exist in the contract", e.Function)
+}
+
+func (e *FunctionLookupError) Unwrap() error {
+	return e.Err
+}
+
+// InputDecodeError is returned when the input of a contract call cannot
+// be decoded
+type InputDecodeError struct {
+	Err error
+}
+
+func (e *InputDecodeError) Error() string {
+	return fmt.Sprintf("failed to parse input JSON: %v", e.Err)
+}
+
+func (e *InputDecodeError) Unwrap() error {
+	return e.Err
+}
+
+// OutputDecodeError is returned when the output written by a contract
+// function cannot be read or decoded
+type OutputDecodeError struct {
+	Function string
+	Err      error
+}
+
+func (e *OutputDecodeError) Error() string {
+	return fmt.Sprintf("failed to decode output of %v: %v", e.Function, e.Err)
+}
+
+func (e *OutputDecodeError) Unwrap() error {
+	return e.Err
+}
+
+// newTrapError classifies an error returned by guest code
+func newTrapError(funcName string, err error, kind TrapKind) *TrapError {
+	trapErr := &TrapError{
+		Function: funcName,
+		Kind:     kind,
+		Message:  err.Error(),
+	}
+
+	var trap *wasmtime.Trap
+	if kind == TrapUnknown && errors.As(err, &trap) {
+		trapErr.Message = trap.Message()
+		if code := trap.Code(); code != nil {
+			if k, ok := trapKinds[*code]; ok {
+				trapErr.Kind = k
+			}
+		}
+	}
+
+	return trapErr
+}
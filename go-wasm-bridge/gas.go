@@ -0,0 +1,131 @@
+package wasmbridge
+
+import (
+	"errors"
+
+	"github.com/bytecodealliance/wasmtime-go"
+)
+
+// ErrOutOfGas is returned when a contract call exhausts the gas (fuel)
+// allotted to it
+var ErrOutOfGas = errors.New("out of gas")
+
+// unlimitedGas is the fuel a call without a gas limit starts with, about
+// 10^12 instructions. Wasmtime accumulates all fuel ever added to a store
+// in an int64 and silently stops adding fuel once it saturates, so the
+// reservoir is kept far below that range: it only needs to outlast any
+// realistic call, and it is refilled before every call.
+const unlimitedGas uint64 = 1 << 40
+
+// WithGasLimit sets the default amount of gas (Wasmtime fuel) a single
+// contract call may consume. A limit of zero means unlimited.
+func WithGasLimit(gasLimit uint64) WasmModuleOption {
+	return func(w *WasmModule) {
+		w.gasLimit = gasLimit
+	}
+}
+
+// CallOption allows us to configure a single contract call
+type CallOption func(*callConfig)
+
+type callConfig struct {
+	gasLimit uint64
+	blockID  string
+}
+
+// WithCallGasLimit overrides the module's gas limit for a single call.
+// A limit of zero means unlimited.
+func WithCallGasLimit(gasLimit uint64) CallOption {
+	return func(c *callConfig) {
+		c.gasLimit = gasLimit
+	}
+}
+
+func (w *WasmModule) newCallConfig(callOpts []CallOption) *callConfig {
+	cfg := &callConfig{
+		gasLimit: w.gasLimit,
+	}
+	for _, opt := range callOpts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// GetGasLimit returns the default gas limit of a contract call
+func (w *WasmModule) GetGasLimit() uint64 {
+	return w.gasLimit
+}
+
+// GasUsed returns the gas consumed by the most recent contract call
+func (w *WasmModule) GasUsed() uint64 {
+	return w.gasUsed
+}
+
+// engineSettings are the settings newEngine changes from the Wasmtime
+// defaults. Their names are part of engineFingerprint, as modules compiled
+// with other settings cannot be reused.
+var engineSettings = []struct {
+	name  string
+	apply func(*wasmtime.Config)
+}{
+	{"consume-fuel", func(config *wasmtime.Config) { config.SetConsumeFuel(true) }},
+	{"epoch-interruption", func(config *wasmtime.Config) { config.SetEpochInterruption(true) }},
+}
+
+// newEngine returns a Wasmtime engine with fuel consumption and epoch
+// interruption enabled
+func newEngine() *wasmtime.Engine {
+	config := wasmtime.NewConfig()
+	for _, setting := range engineSettings {
+		setting.apply(config)
+	}
+	return wasmtime.NewEngineWithConfig(config)
+}
+
+// setFuel tops up or drains the store so that exactly gasLimit units of
+// fuel remain available to the guest. A limit of zero means unlimited, in
+// which case the store holds unlimitedGas.
+func (w *WasmModule) setFuel(gasLimit uint64) error {
+	target := gasLimit
+	if target == 0 || target > unlimitedGas {
+		target = unlimitedGas
+	}
+
+	// A guest which ran out of fuel may have overshot it, which is only
+	// noticed once fuel has been added, so the store is checked again
+	for i := 0; i < 3; i++ {
+		remaining := remainingFuel(w.store)
+		switch {
+		case remaining == target:
+			return nil
+		case remaining > target:
+			if _, err := w.store.ConsumeFuel(remaining - target); err != nil {
+				return err
+			}
+		default:
+			if err := w.store.AddFuel(target - remaining); err != nil {
+				return err
+			}
+		}
+	}
+	if remainingFuel(w.store) != target {
+		return errors.New("fuel of the store is exhausted, the module must be reloaded")
+	}
+	return nil
+}
+
+// remainingFuel returns the fuel left in the store. Wasmtime reports an
+// error instead of the remaining fuel once the store has run dry.
+func remainingFuel(store *wasmtime.Store) uint64 {
+	remaining, err := store.ConsumeFuel(0)
+	if err != nil {
+		return 0
+	}
+	return remaining
+}
+
+// fuelConsumed returns the total fuel consumed by the store so far
+func fuelConsumed(store *wasmtime.Store) uint64 {
+	consumed, _ := store.FuelConsumed()
+	return consumed
+}
@@ -0,0 +1,71 @@
+package wasmbridge
+
+import (
+	"errors"
+	"testing"
+)
+
+// burnFunc loops 1000 times and returns "ok"
+const burnFunc = `(func (export "burn_") (param i32 i32 i32 i32) (result i32)
+    (local $i i32)
+    (loop $l
+      local.get $i
+      i32.const 1
+      i32.add
+      local.tee $i
+      i32.const 1000
+      i32.lt_u
+      br_if $l)
+    local.get 2
+    i32.const 16
+    i32.store
+    local.get 3
+    i32.const 4
+    i32.store
+    i32.const 0)`
+
+// burnCall is the input of a call to burnFunc
+const burnCall = `{"burn": {}}`
+
+func TestGasExhaustion(t *testing.T) {
+	module := newTestModule(t, testContract("", burnFunc), WithGasLimit(100))
+
+	_, err := module.CallFunction(burnCall)
+	if !errors.Is(err, ErrOutOfGas) {
+		t.Fatalf("expected ErrOutOfGas, got %v", err)
+	}
+	if module.GasUsed() < 100 {
+		t.Errorf("expected at least 100 gas to be used, got %d", module.GasUsed())
+	}
+}
+
+func TestGasUsedWithinLimit(t *testing.T) {
+	module := newTestModule(t, testContract("", burnFunc))
+
+	output, err := module.CallFunction(burnCall, WithCallGasLimit(1_000_000))
+	if err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if output != "ok" {
+		t.Errorf("expected output ok, got %q", output)
+	}
+	if module.GasUsed() == 0 || module.GasUsed() > 1_000_000 {
+		t.Errorf("unexpected gas used %d", module.GasUsed())
+	}
+}
+
+func TestAlternatingGasLimits(t *testing.T) {
+	module := newTestModule(t, testContract("", burnFunc))
+
+	for i := 0; i < 50; i++ {
+		if _, err := module.CallFunction(burnCall, WithCallGasLimit(1_000_000)); err != nil {
+			t.Fatalf("iteration %d: limited call failed: %v", i, err)
+		}
+		if _, err := module.CallFunction(burnCall); err != nil {
+			t.Fatalf("iteration %d: unlimited call failed: %v", i, err)
+		}
+		if _, err := module.CallFunction(burnCall, WithCallGasLimit(100)); !errors.Is(err, ErrOutOfGas) {
+			t.Fatalf("iteration %d: expected ErrOutOfGas, got %v", i, err)
+		}
+	}
+}
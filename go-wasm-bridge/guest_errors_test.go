@@ -0,0 +1,91 @@
+package wasmbridge
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bytecodealliance/wasmtime-go"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host"
+)
+
+// guestErrorRegistry returns a registry which records the response of
+// every host function call in responses
+func guestErrorRegistry(responses *[]host.GuestError) *HostFunctionRegistry {
+	registry := NewHostFunctionRegistry()
+	registry.Use(func(next host.Handler) host.Handler {
+		return func(inv *host.Invocation) ([]wasmtime.Val, *wasmtime.Trap) {
+			results, trap := next(inv)
+			var guestErr host.GuestError
+			if trap == nil && json.Unmarshal(inv.Output(), &guestErr) == nil {
+				*responses = append(*responses, guestErr)
+			}
+			return results, trap
+		}
+	})
+	return registry
+}
+
+func TestGuestErrors(t *testing.T) {
+	var responses []host.GuestError
+	module, err := NewWasmModuleFromBytes(emitContract, guestErrorRegistry(&responses), WithGuestErrors())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The contract sees the error of emit_event and returns its code
+	result, err := module.Call("emit_event", []byte(`{"data": "42"}`))
+	var contractErr *ContractError
+	if !errors.As(err, &contractErr) || contractErr.Code != host.GuestErrorCode {
+		t.Fatalf("expected a ContractError with code %d, got %v", host.GuestErrorCode, err)
+	}
+	if len(responses) != 1 || responses[0].Code != host.GuestErrorCode || !strings.Contains(responses[0].Message, "event name") {
+		t.Errorf("unexpected error payload %+v", responses)
+	}
+	if len(result.Logs) != 1 || !strings.Contains(result.Logs[0], "event name") {
+		t.Errorf("expected the error to be logged, got %v", result.Logs)
+	}
+}
+
+func TestHostErrorsTrapWithoutGuestErrors(t *testing.T) {
+	module := newTestModule(t, emitContract)
+
+	var hostErr *HostFunctionError
+	if _, err := module.Call("emit_event", []byte(`{"data": "42"}`)); !errors.As(err, &hostErr) {
+		t.Fatalf("expected a HostFunctionError, got %v", err)
+	}
+}
+
+func TestPermissionDeniedGuestError(t *testing.T) {
+	var responses []host.GuestError
+	module, err := NewWasmModuleFromBytes(emitContract, guestErrorRegistry(&responses), WithGuestErrors(), WithCapabilities("http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := module.Call("emit_event", []byte(`{"name": "bid"}`))
+	var contractErr *ContractError
+	if !errors.As(err, &contractErr) || contractErr.Code != CodePermissionDenied {
+		t.Fatalf("expected a ContractError with code %d, got %v", CodePermissionDenied, err)
+	}
+	if len(responses) != 1 || responses[0].Code != CodePermissionDenied || !strings.Contains(responses[0].Message, ErrPermissionDenied.Error()) {
+		t.Errorf("unexpected error payload %+v", responses)
+	}
+	if len(result.Events) != 0 {
+		t.Errorf("expected no events, got %+v", result.Events)
+	}
+}
+
+func TestBadPointersTrapWithGuestErrors(t *testing.T) {
+	// Bad pointers are ABI violations, which trap in every error mode
+	module := newTestModule(t, testContract(
+		hostImport("env", "do_api_call"),
+		pointerCallFunc("negativelen", "do_api_call", 100, -50),
+	), WithGuestErrors())
+
+	var hostErr *HostFunctionError
+	if _, err := module.Call("negativelen", []byte(`{}`)); !errors.As(err, &hostErr) {
+		t.Errorf("expected a HostFunctionError, got %v", err)
+	}
+}
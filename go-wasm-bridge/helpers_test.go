@@ -0,0 +1,172 @@
+package wasmbridge
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bytecodealliance/wasmtime-go"
+)
+
+// testContractTemplate is the boilerplate every test contract needs: an
+// exported memory, a bump allocator and a no-op dealloc. The string "ok",
+// JSON encoded, is stored at offset 16. %s receives the imports and
+// functions of the contract.
+const testContractTemplate = `(module
+  %s
+  (memory (export "memory") 2)
+  (global $heap (mut i32) (i32.const 1024))
+  (data (i32.const 16) "\"ok\"")
+  (func $alloc (export "alloc") (param $n i32) (result i32)
+    (local $p i32)
+    global.get $heap
+    local.set $p
+    global.get $heap
+    local.get $n
+    i32.add
+    i32.const 7
+    i32.add
+    i32.const -8
+    i32.and
+    global.set $heap
+    local.get $p)
+  (func (export "dealloc") (param i32 i32))
+  %s
+)`
+
+// Contract functions shared by the tests
+const (
+	// okFunc returns "ok"
+	okFunc = `(func (export "ok_") (param i32 i32 i32 i32) (result i32)
+    local.get 2
+    i32.const 16
+    i32.store
+    local.get 3
+    i32.const 4
+    i32.store
+    i32.const 0)`
+
+	// failFunc returns "ok" with return code 1
+	failFunc = `(func (export "fail_") (param i32 i32 i32 i32) (result i32)
+    local.get 2
+    i32.const 16
+    i32.store
+    local.get 3
+    i32.const 4
+    i32.store
+    i32.const 1)`
+
+	// echoFunc returns its input as output
+	echoFunc = `(func (export "echo_") (param i32 i32 i32 i32) (result i32)
+    local.get 2
+    local.get 0
+    i32.store
+    local.get 3
+    local.get 1
+    i32.store
+    i32.const 0)`
+
+	// spinFunc never returns
+	spinFunc = `(func (export "spin_") (param i32 i32 i32 i32) (result i32)
+    (loop br 0)
+    i32.const 0)`
+
+	// slowFunc returns "ok" after counting down from 100 million
+	slowFunc = `(func (export "slow_") (param i32 i32 i32 i32) (result i32)
+    (local $n i32)
+    i32.const 100000000
+    local.set $n
+    (loop
+      local.get $n
+      i32.const 1
+      i32.sub
+      local.tee $n
+      br_if 0)
+    local.get 2
+    i32.const 16
+    i32.store
+    local.get 3
+    i32.const 4
+    i32.store
+    i32.const 0)`
+
+	// trapFunc traps unconditionally
+	trapFunc = `(func (export "trap_") (param i32 i32 i32 i32) (result i32)
+    unreachable)`
+)
+
+// hostImport returns the import of a host function with the standard
+// (input_ptr, input_len, resp_ptr_ptr, resp_len_ptr) -> i32 signature
+func hostImport(namespace, name string) string {
+	return fmt.Sprintf(`(import %q %q (func $%s (param i32 i32 i32 i32) (result i32)))`, namespace, name, name)
+}
+
+// hostCallFunc returns the contract function <name>_, which passes its
+// input to the imported host function $<name>. It returns "ok", or the
+// return code of the host function if it is not zero.
+func hostCallFunc(name string) string {
+	return fmt.Sprintf(`(func (export "%s_") (param i32 i32 i32 i32) (result i32)
+    (local $rc i32)
+    local.get 0
+    local.get 1
+    local.get 2
+    local.get 3
+    call $%s
+    local.set $rc
+    local.get 2
+    i32.const 16
+    i32.store
+    local.get 3
+    i32.const 4
+    i32.store
+    local.get $rc)`, name, name)
+}
+
+// testContract returns the WAT text of a contract with the given imports
+// and functions
+func testContract(imports string, funcs ...string) []byte {
+	body := ""
+	for _, f := range funcs {
+		body += f + "\n  "
+	}
+	return []byte(fmt.Sprintf(testContractTemplate, imports, body))
+}
+
+// writeTestContract compiles a test contract and returns the path of the
+// WASM file
+func writeTestContract(t *testing.T, contract []byte) string {
+	t.Helper()
+
+	wasmBytes, err := wasmtime.Wat2Wasm(string(contract))
+	if err != nil {
+		t.Fatalf("failed to compile test contract: %v", err)
+	}
+	wasmFilePath := filepath.Join(t.TempDir(), "contract.wasm")
+	if err := os.WriteFile(wasmFilePath, wasmBytes, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return wasmFilePath
+}
+
+// newTestModule loads a test contract with the default host functions
+func newTestModule(t *testing.T, contract []byte, opts ...WasmModuleOption) *WasmModule {
+	t.Helper()
+
+	module, err := NewWasmModuleFromBytes(contract, NewHostFunctionRegistry(), opts...)
+	if err != nil {
+		t.Fatalf("failed to load test contract: %v", err)
+	}
+	return module
+}
+
+// newTestPool loads a test contract into a pool of the given size
+func newTestPool(t *testing.T, contract []byte, size int, opts ...WasmModuleOption) *WasmPool {
+	t.Helper()
+
+	pool, err := NewWasmPoolFromBytes(contract, nil, size, opts...)
+	if err != nil {
+		t.Fatalf("failed to load test contract into a pool: %v", err)
+	}
+	return pool
+}
@@ -0,0 +1,29 @@
+package host
+
+import "fmt"
+
+// GuestErrorCode is the return code of a host function which failed
+// without a more specific code
+const GuestErrorCode int32 = 1
+
+// GuestError is an error a host function hands back to the contract
+// instead of trapping. The contract receives it as the JSON payload
+// {"code": <code>, "message": <message>} along with a non-zero return
+// code, and may recover from it.
+type GuestError struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewGuestError returns a GuestError with the given code, which must not
+// be zero
+func NewGuestError(code int32, format string, args ...interface{}) *GuestError {
+	if code == 0 {
+		code = GuestErrorCode
+	}
+	return &GuestError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *GuestError) Error() string {
+	return fmt.Sprintf("host error %d: %v", e.Code, e.Message)
+}
@@ -0,0 +1,29 @@
+package events
+
+import (
+	"errors"
+
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host"
+)
+
+// EventReq is the input of emit_event
+type EventReq struct {
+	Name string `json:"name"`
+	Data string `json:"data"`
+}
+
+// NewEmitEvent returns the emit_event host function, which records an
+// event in the CallResult of the contract call
+func NewEmitEvent() host.HostFunction {
+	return host.NewJSONFunc("emit_event", func(ctx *host.CallContext, req EventReq) (string, error) {
+		if req.Name == "" {
+			return "", errors.New("event name must not be empty")
+		}
+		if ctx.WasmCtx == nil {
+			return "", errors.New("contract has no context to record events in")
+		}
+
+		ctx.WasmCtx.EmitEvent(req.Name, req.Data)
+		return "", nil
+	}, host.WithCapability("events"))
+}
@@ -5,6 +5,10 @@ import (
 	wasmContext "github.com/rubixchain/rubix-wasm/go-wasm-bridge/context"
 )
 
+// DefaultNamespace is the import module of host functions which do not
+// declare a namespace
+const DefaultNamespace = "env"
+
 type HostFunctionCallBack = func(*wasmtime.Caller, []wasmtime.Val) ([]wasmtime.Val, *wasmtime.Trap)
 
 type HostFunction interface {
@@ -24,3 +28,42 @@ type HostFunction interface {
 		wasmCtx *wasmContext.WasmContext,
 	)
 }
+
+// HostFunctionFactory creates a new HostFunction instance, which is bound
+// to a single WASM module through Initialize
+type HostFunctionFactory func() HostFunction
+
+// Cloner is implemented by host functions which know how to copy
+// themselves for another WASM module
+type Cloner interface {
+	Clone() HostFunction
+}
+
+// Namespaced is implemented by host functions which are imported from a
+// module other than DefaultNamespace
+type Namespaced interface {
+	Namespace() string
+}
+
+// NamespaceOf returns the import module hf is defined under
+func NamespaceOf(hf HostFunction) string {
+	if namespaced, ok := hf.(Namespaced); ok && namespaced.Namespace() != "" {
+		return namespaced.Namespace()
+	}
+	return DefaultNamespace
+}
+
+// Capable is implemented by host functions which declare the capability a
+// contract needs to call them, such as "http" or "ft.transfer"
+type Capable interface {
+	Capability() string
+}
+
+// CapabilityOf returns the capability required to call hf. Host functions
+// which do not declare one require "<namespace>.<name>".
+func CapabilityOf(hf HostFunction) string {
+	if capable, ok := hf.(Capable); ok && capable.Capability() != "" {
+		return capable.Capability()
+	}
+	return NamespaceOf(hf) + "." + hf.Name()
+}
@@ -0,0 +1,259 @@
+package host
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/bytecodealliance/wasmtime-go"
+	wasmContext "github.com/rubixchain/rubix-wasm/go-wasm-bridge/context"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/rubixclient"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/utils"
+)
+
+// CallContext describes a single invocation of a JSON host function
+type CallContext struct {
+	Caller      *wasmtime.Caller
+	WasmCtx     *wasmContext.WasmContext
+	NodeAddress string
+	QuorumType  int
+}
+
+// Context returns the context.Context of the contract call, which is
+// cancelled along with it
+func (c *CallContext) Context() context.Context {
+	if c.WasmCtx == nil {
+		return context.Background()
+	}
+	return c.WasmCtx
+}
+
+// Client returns the client to call the Rubix node with: the one set on
+// the WasmContext, or the shared client for NodeAddress
+func (c *CallContext) Client() *rubixclient.Client {
+	if c.WasmCtx != nil && c.WasmCtx.RubixClient() != nil {
+		return c.WasmCtx.RubixClient()
+	}
+	return rubixclient.ForNode(c.NodeAddress)
+}
+
+// JSONHandler implements the logic of a JSON host function
+type JSONHandler[In, Out any] func(ctx *CallContext, in In) (Out, error)
+
+// JSONFuncOption configures a JSON host function
+type JSONFuncOption func(*jsonFuncConfig)
+
+type jsonFuncConfig struct {
+	namespace  string
+	capability string
+}
+
+// WithNamespace defines the host function under the namespace import
+// module instead of DefaultNamespace
+func WithNamespace(namespace string) JSONFuncOption {
+	return func(cfg *jsonFuncConfig) {
+		cfg.namespace = namespace
+	}
+}
+
+// WithCapability sets the capability a contract needs to call the host
+// function
+func WithCapability(capability string) JSONFuncOption {
+	return func(cfg *jsonFuncConfig) {
+		cfg.capability = capability
+	}
+}
+
+// jsonFunc is a HostFunction which exchanges JSON with the contract
+type jsonFunc[In, Out any] struct {
+	name    string
+	handler JSONHandler[In, Out]
+	opts    []JSONFuncOption
+	cfg     jsonFuncConfig
+
+	nodeAddress string
+	quorumType  int
+	wasmCtx     *wasmContext.WasmContext
+}
+
+// NewJSONFunc returns a HostFunction with the standard signature
+// (input_ptr, input_len, resp_ptr_ptr, resp_len_ptr) -> i32. The input is
+// decoded into In and the Out returned by handler is encoded as the
+// response. A string, []byte or json.RawMessage In or Out is passed
+// through as is. Errors returned by handler trap the contract, unless they
+// are a GuestError or the module hands host errors back to the contract.
+func NewJSONFunc[In, Out any](name string, handler JSONHandler[In, Out], opts ...JSONFuncOption) HostFunction {
+	h := &jsonFunc[In, Out]{
+		name:    name,
+		handler: handler,
+		opts:    opts,
+		cfg:     jsonFuncConfig{namespace: DefaultNamespace},
+	}
+	for _, opt := range opts {
+		opt(&h.cfg)
+	}
+	return h
+}
+
+func (h *jsonFunc[In, Out]) Name() string {
+	return h.name
+}
+
+func (h *jsonFunc[In, Out]) Namespace() string {
+	return h.cfg.namespace
+}
+
+func (h *jsonFunc[In, Out]) Capability() string {
+	return h.cfg.capability
+}
+
+func (h *jsonFunc[In, Out]) FuncType() *wasmtime.FuncType {
+	return wasmtime.NewFuncType(
+		[]*wasmtime.ValType{
+			wasmtime.NewValType(wasmtime.KindI32), // input_ptr
+			wasmtime.NewValType(wasmtime.KindI32), // input_len
+			wasmtime.NewValType(wasmtime.KindI32), // resp_ptr_ptr
+			wasmtime.NewValType(wasmtime.KindI32), // resp_len_ptr
+		},
+		[]*wasmtime.ValType{wasmtime.NewValType(wasmtime.KindI32)}, // return i32
+	)
+}
+
+func (h *jsonFunc[In, Out]) Initialize(allocFunc, deallocFunc *wasmtime.Func, memory *wasmtime.Memory, nodeAddress string, quorumType int, wasmCtx *wasmContext.WasmContext) {
+	h.nodeAddress = nodeAddress
+	h.quorumType = quorumType
+	h.wasmCtx = wasmCtx
+}
+
+// Clone returns an unbound copy of the host function
+func (h *jsonFunc[In, Out]) Clone() HostFunction {
+	return NewJSONFunc(h.name, h.handler, h.opts...)
+}
+
+func (h *jsonFunc[In, Out]) Callback() HostFunctionCallBack {
+	return h.callback
+}
+
+func (h *jsonFunc[In, Out]) callback(
+	caller *wasmtime.Caller,
+	args []wasmtime.Val,
+) (results []wasmtime.Val, trap *wasmtime.Trap) {
+	// Pointers and lengths come from the guest, a bug in checking them
+	// must trap the contract instead of crashing the host
+	defer func() {
+		if r := recover(); r != nil {
+			results, trap = utils.HandleError(fmt.Sprintf("%v panicked: %v", h.name, r))
+		}
+	}()
+
+	if len(args) != 4 {
+		return utils.HandleError(fmt.Sprintf("%v expects 4 arguments, got %d", h.name, len(args)))
+	}
+	inputArgs, outputArgs, err := utils.HostFunctionParamExtraction(args, true, true)
+	if err != nil {
+		return utils.HandleError(err.Error())
+	}
+
+	inputBytes, _, err := utils.ExtractDataFromWASM(caller, inputArgs)
+	if err != nil {
+		return utils.HandleError(err.Error())
+	}
+
+	// The contract's own allocator is used, as alloc may differ per store
+	allocExport := caller.GetExport("alloc")
+	if allocExport == nil || allocExport.Func() == nil {
+		return utils.HandleError("alloc export not found")
+	}
+	allocFunc := allocExport.Func()
+
+	var in In
+	if err := decodeJSONInput(inputBytes, &in); err != nil {
+		return h.fail(caller, allocFunc, outputArgs, fmt.Errorf("failed to decode input of %v: %w", h.name, err))
+	}
+
+	out, err := h.invoke(caller, in)
+	if err != nil {
+		return h.fail(caller, allocFunc, outputArgs, err)
+	}
+
+	outputBytes, err := encodeJSONOutput(out)
+	if err != nil {
+		return utils.HandleError(fmt.Sprintf("failed to encode response of %v: %v", h.name, err))
+	}
+
+	if err := utils.UpdateDataToWASM(caller, allocFunc, string(outputBytes), outputArgs); err != nil {
+		return utils.HandleError(err.Error())
+	}
+
+	return utils.HandleOk()
+}
+
+// fail hands err back to the contract if it is a GuestError or the module
+// returns host errors to the contract. Otherwise it records err as the
+// cause of the failed call, so that it is reported as
+// HostFunctionError.Err, and traps the contract.
+func (h *jsonFunc[In, Out]) fail(caller *wasmtime.Caller, allocFunc *wasmtime.Func, outputArgs *utils.WasmArgInfo, err error) ([]wasmtime.Val, *wasmtime.Trap) {
+	var guestErr *GuestError
+	if !errors.As(err, &guestErr) {
+		if h.wasmCtx == nil || !h.wasmCtx.GuestErrors() {
+			if h.wasmCtx != nil {
+				h.wasmCtx.SetHostError(err)
+			}
+			return utils.HandleError(err.Error())
+		}
+		guestErr = NewGuestError(GuestErrorCode, "%v", err)
+	}
+
+	if h.wasmCtx != nil {
+		h.wasmCtx.Log(fmt.Sprintf("%v: %v", h.name, guestErr))
+	}
+	return utils.HandleGuestError(caller, allocFunc, outputArgs, guestErr.Code, guestErr.Message)
+}
+
+// invoke runs the handler, turning a panic into an error so that it
+// traps the contract instead of crashing the host
+func (h *jsonFunc[In, Out]) invoke(caller *wasmtime.Caller, in In) (out Out, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v panicked: %v", h.name, r)
+		}
+	}()
+
+	return h.handler(&CallContext{
+		Caller:      caller,
+		WasmCtx:     h.wasmCtx,
+		NodeAddress: h.nodeAddress,
+		QuorumType:  h.quorumType,
+	}, in)
+}
+
+func decodeJSONInput(data []byte, in any) error {
+	switch v := in.(type) {
+	case *string:
+		*v = string(data)
+	case *[]byte:
+		*v = append([]byte(nil), data...)
+	case *json.RawMessage:
+		*v = append(json.RawMessage(nil), data...)
+	default:
+		if len(data) == 0 {
+			return errors.New("empty input")
+		}
+		return json.Unmarshal(data, in)
+	}
+	return nil
+}
+
+func encodeJSONOutput(out any) ([]byte, error) {
+	switch v := out.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	case json.RawMessage:
+		return v, nil
+	default:
+		return json.Marshal(out)
+	}
+}
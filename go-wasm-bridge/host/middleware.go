@@ -0,0 +1,114 @@
+package host
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/bytecodealliance/wasmtime-go"
+	wasmContext "github.com/rubixchain/rubix-wasm/go-wasm-bridge/context"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/utils"
+)
+
+// Invocation describes a single call of a host function by a contract
+type Invocation struct {
+	Namespace string
+	Name      string
+	Caller    *wasmtime.Caller
+	Args      []wasmtime.Val
+	WasmCtx   *wasmContext.WasmContext
+}
+
+// Handler handles an Invocation and returns the results of the host
+// function
+type Handler func(inv *Invocation) ([]wasmtime.Val, *wasmtime.Trap)
+
+// Middleware wraps a Handler with cross-cutting behaviour. It must call
+// next to run the host function.
+type Middleware func(next Handler) Handler
+
+// Chain wraps callback with middlewares, the first of which is the
+// outermost. inv describes the host function, its Caller and Args are
+// filled in on every call.
+func Chain(inv Invocation, callback HostFunctionCallBack, middlewares []Middleware) HostFunctionCallBack {
+	if len(middlewares) == 0 {
+		return callback
+	}
+
+	handler := Handler(func(inv *Invocation) ([]wasmtime.Val, *wasmtime.Trap) {
+		return callback(inv.Caller, inv.Args)
+	})
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return func(caller *wasmtime.Caller, args []wasmtime.Val) ([]wasmtime.Val, *wasmtime.Trap) {
+		call := inv
+		call.Caller = caller
+		call.Args = args
+		return handler(&call)
+	}
+}
+
+// Input returns a copy of the input the contract passed to a host
+// function with the standard (input_ptr, input_len, resp_ptr_ptr,
+// resp_len_ptr) signature, or nil for other signatures
+func (inv *Invocation) Input() []byte {
+	if len(inv.Args) != 4 {
+		return nil
+	}
+	inputArgs, _, err := utils.HostFunctionParamExtraction(inv.Args, true, true)
+	if err != nil {
+		return nil
+	}
+
+	input, _, err := utils.ExtractDataFromWASM(inv.Caller, inputArgs)
+	if err != nil {
+		return nil
+	}
+	return append([]byte(nil), input...)
+}
+
+// Output returns a copy of the response a host function with the
+// standard signature wrote back to the contract. It is only meaningful
+// once next has returned without a trap.
+func (inv *Invocation) Output() []byte {
+	if len(inv.Args) != 4 {
+		return nil
+	}
+	_, outputArgs, err := utils.HostFunctionParamExtraction(inv.Args, true, true)
+	if err != nil {
+		return nil
+	}
+
+	memory := inv.Caller.GetExport("memory")
+	if memory == nil || memory.Memory() == nil {
+		return nil
+	}
+	data := memory.Memory().UnsafeData(inv.Caller)
+
+	respPtrPtr, respLenPtr := int(outputArgs.DataPtr), int(outputArgs.DataPtrSize)
+	if respPtrPtr < 0 || respPtrPtr+4 > len(data) || respLenPtr < 0 || respLenPtr+4 > len(data) {
+		return nil
+	}
+	respPtr := int(binary.LittleEndian.Uint32(data[respPtrPtr:]))
+	respLen := int(binary.LittleEndian.Uint32(data[respLenPtr:]))
+	if respPtr+respLen > len(data) {
+		return nil
+	}
+	return append([]byte(nil), data[respPtr:respPtr+respLen]...)
+}
+
+// Recover is a Middleware which turns a panic in a host function into a
+// trap instead of crashing the host
+func Recover() Middleware {
+	return func(next Handler) Handler {
+		return func(inv *Invocation) (results []wasmtime.Val, trap *wasmtime.Trap) {
+			defer func() {
+				if r := recover(); r != nil {
+					results, trap = utils.HandleError(fmt.Sprintf("%v panicked: %v", inv.Name, r))
+				}
+			}()
+			return next(inv)
+		}
+	}
+}
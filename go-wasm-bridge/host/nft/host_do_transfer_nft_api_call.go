@@ -1,17 +1,10 @@
 package nft
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
 
-	"github.com/bytecodealliance/wasmtime-go"
 	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host"
-	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/utils"
-	wasmContext "github.com/rubixchain/rubix-wasm/go-wasm-bridge/context"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/rubixclient"
 )
 
 type TransferNFTData struct {
@@ -24,129 +17,45 @@ type TransferNFTData struct {
 	QuorumType int32   `json:"quorum_type"`
 }
 
+// DoTransferNFTApiCall lets contracts transfer NFTs
 type DoTransferNFTApiCall struct {
-	allocFunc   *wasmtime.Func
-	memory      *wasmtime.Memory
-	nodeAddress string
-	quorumType  int
+	host.HostFunction
 }
 
 func NewDoTransferNFTApiCall() *DoTransferNFTApiCall {
-	return &DoTransferNFTApiCall{}
-}
-func (h *DoTransferNFTApiCall) Name() string {
-	return "do_transfer_nft"
-}
-func (h *DoTransferNFTApiCall) FuncType() *wasmtime.FuncType {
-	return wasmtime.NewFuncType(
-		[]*wasmtime.ValType{
-			wasmtime.NewValType(wasmtime.KindI32), // input_ptr
-			wasmtime.NewValType(wasmtime.KindI32), // input_len
-			wasmtime.NewValType(wasmtime.KindI32), // resp_ptr_ptr
-			wasmtime.NewValType(wasmtime.KindI32), // resp_len_ptr
-		},
-		[]*wasmtime.ValType{wasmtime.NewValType(wasmtime.KindI32)}, // return i32
-	)
+	return &DoTransferNFTApiCall{host.NewJSONFunc("do_transfer_nft", doTransferNFT)}
 }
 
-func (h *DoTransferNFTApiCall) Initialize(allocFunc, deallocFunc *wasmtime.Func, memory *wasmtime.Memory, nodeAddress string, quorumType int, wasmCtx *wasmContext.WasmContext) {
-	h.allocFunc = allocFunc
-	h.memory = memory
-	h.nodeAddress = nodeAddress
-	h.quorumType = quorumType
+// Clone returns an unbound copy of the host function
+func (h *DoTransferNFTApiCall) Clone() host.HostFunction {
+	return NewDoTransferNFTApiCall()
 }
 
-func (h *DoTransferNFTApiCall) Callback() host.HostFunctionCallBack {
-	return h.callback
+func (h *DoTransferNFTApiCall) Capability() string {
+	return "nft.transfer"
 }
-func callTransferNFTAPI(nodeAddress string, quorumType int, transferNFTdata TransferNFTData) error {
-	transferNFTdata.QuorumType = int32(quorumType)
-	fmt.Println("printing the data in callTransferNFTAPI function is:", transferNFTdata)
-	bodyJSON, err := json.Marshal(transferNFTdata)
-	if err != nil {
-		fmt.Println("Error marshaling JSON:", err)
-		return err
-	}
 
-	transferNFTUrl, err := url.JoinPath(nodeAddress, "/api/execute-nft")
-	if err != nil {
-		return err
+func doTransferNFT(ctx *host.CallContext, transferNFTData TransferNFTData) (string, error) {
+	if err := ctx.CheckSigner(); err != nil {
+		return "", err
 	}
 
-	req, err := http.NewRequest("POST", transferNFTUrl, bytes.NewBuffer(bodyJSON))
-	if err != nil {
-		fmt.Println("Error creating HTTP request:", err)
-		return err
+	executeNFTReq := rubixclient.ExecuteNFTRequest{
+		NFT:        transferNFTData.NFT,
+		Owner:      transferNFTData.Owner,
+		Receiver:   transferNFTData.Receiver,
+		Comment:    transferNFTData.Comment,
+		NFTValue:   transferNFTData.NFTValue,
+		NFTData:    transferNFTData.NFTData,
+		QuorumType: int32(ctx.QuorumType),
 	}
-
-	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		fmt.Println("Error sending HTTP request:", err)
-		return err
-	}
-	fmt.Println("Response Status in callTransferNFTAPI:", resp.Status)
-	data2, err := io.ReadAll(resp.Body)
+	executeNFTResp, err := ctx.Client().ExecuteNFT(ctx.Context(), executeNFTReq)
 	if err != nil {
-		fmt.Printf("Error reading response body: %s\n", err)
-		return err
+		return "", fmt.Errorf("failed to transfer NFT: %w", err)
 	}
-	// Process the data as needed
-	fmt.Println("Response Body in callTransferNFTAPI :", string(data2))
-	var response map[string]interface{}
-	err3 := json.Unmarshal(data2, &response)
-	if err3 != nil {
-		fmt.Println("Error unmarshaling response:", err3)
-		return err3
-	}
-
-	result := response["result"].(map[string]interface{})
-	id := result["id"].(string)
-
-	defer resp.Body.Close()
 
-	_, err = utils.SignatureResponse(id, nodeAddress)
-	return err
-}
-
-func (h *DoTransferNFTApiCall) callback(
-	caller *wasmtime.Caller,
-	args []wasmtime.Val,
-) ([]wasmtime.Val, *wasmtime.Trap) {
-	inputArgs, outputArgs := utils.HostFunctionParamExtraction(args, true, true)
-
-	// Extract input bytes and convert to string
-	inputBytes, memory, err := utils.ExtractDataFromWASM(caller, inputArgs)
-	if err != nil {
-		fmt.Println("Failed to extract data from WASM", err)
-		return utils.HandleError(err.Error())
-	}
-	h.memory = memory // Assign memory to Host struct for future use
-	var transferNFTData TransferNFTData
-
-	//Unmarshaling the data which has been read from the wasm memory
-	err3 := json.Unmarshal(inputBytes, &transferNFTData)
-	if err3 != nil {
-		fmt.Println("Error unmarshaling response in callback function:", err3)
-		errMsg := "Error unmashalling response in callback function" + err3.Error()
-		return utils.HandleError(errMsg)
+	if _, err := ctx.SignPending("nft.transfer", executeNFTResp.Result.ID, executeNFTReq); err != nil {
+		return "", fmt.Errorf("failed to transfer NFT: %w", err)
 	}
-	callTransferNFTAPIRespErr := callTransferNFTAPI(h.nodeAddress, h.quorumType, transferNFTData)
-	if callTransferNFTAPIRespErr != nil {
-		fmt.Println("failed to transfer NFT", callTransferNFTAPIRespErr)
-		errMsg := "failed to transfer NFT" + callTransferNFTAPIRespErr.Error()
-		return utils.HandleError(errMsg)
-	}
-
-	responseStr := "success"
-	err = utils.UpdateDataToWASM(caller, h.allocFunc, responseStr, outputArgs)
-	if err != nil {
-		fmt.Println("Failed to update data to WASM", err)
-		return utils.HandleError(err.Error())
-	}
-
-	return utils.HandleOk() // Success
-
+	return "success", nil
 }
@@ -0,0 +1,47 @@
+package host
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/rubixclient"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/signer"
+)
+
+// CheckSigner returns signer.ErrNoSigner if the contract has no signer.
+// Host functions call it before starting a transaction which needs a
+// signature, so that no transaction is left pending on the node.
+func (c *CallContext) CheckSigner() error {
+	if c.WasmCtx == nil || c.WasmCtx.Signer() == nil {
+		return signer.ErrNoSigner
+	}
+	return nil
+}
+
+// SignPending answers the signature request requestID of a pending
+// transaction with the signer of the contract. operation and details
+// describe the transaction to the signer, see signer.Transaction.
+func (c *CallContext) SignPending(operation, requestID string, details any) (*rubixclient.Response[json.RawMessage], error) {
+	if err := c.CheckSigner(); err != nil {
+		return nil, err
+	}
+
+	client := c.Client()
+	tx := signer.Transaction{
+		RequestID:   requestID,
+		Operation:   operation,
+		ContractID:  c.WasmCtx.ContractID(),
+		NodeAddress: client.NodeAddress(),
+		Details:     details,
+	}
+	signature, err := c.WasmCtx.Signer().Sign(c.Context(), tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign %v request %v: %w", operation, requestID, err)
+	}
+
+	return client.SignatureResponse(c.Context(), rubixclient.SignatureRequest{
+		ID:       requestID,
+		Mode:     signature.Mode,
+		Password: signature.Password,
+	})
+}
@@ -0,0 +1,96 @@
+package state
+
+import (
+	"errors"
+
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host"
+	wasmState "github.com/rubixchain/rubix-wasm/go-wasm-bridge/state"
+)
+
+const (
+	// CodeKeyNotFound is the guest error code of state_get for a missing key
+	CodeKeyNotFound int32 = 404
+
+	// CodeQuotaExceeded is the guest error code of state_set for a write
+	// which exceeds the state quota of the contract
+	CodeQuotaExceeded int32 = 507
+)
+
+// KeyReq is the input of state_get, state_delete and state_has
+type KeyReq struct {
+	Key string `json:"key"`
+}
+
+// SetReq is the input of state_set
+type SetReq struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// NewStateGet returns the state_get host function, which responds with
+// the value of a key. A missing key is handed back to the contract as a
+// guest error with code CodeKeyNotFound.
+func NewStateGet() host.HostFunction {
+	return host.NewJSONFunc("state_get", func(ctx *host.CallContext, req KeyReq) ([]byte, error) {
+		store, contractID, err := contractState(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := store.Get(contractID, req.Key)
+		if errors.Is(err, wasmState.ErrKeyNotFound) {
+			return nil, host.NewGuestError(CodeKeyNotFound, "state key %q not found", req.Key)
+		}
+		return value, err
+	}, host.WithCapability("state.read"))
+}
+
+// NewStateSet returns the state_set host function, which stores a value
+func NewStateSet() host.HostFunction {
+	return host.NewJSONFunc("state_set", func(ctx *host.CallContext, req SetReq) (string, error) {
+		store, contractID, err := contractState(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		err = store.Set(contractID, req.Key, []byte(req.Value))
+		if errors.Is(err, wasmState.ErrQuotaExceeded) {
+			return "", host.NewGuestError(CodeQuotaExceeded, "%v", err)
+		}
+		return "", err
+	}, host.WithCapability("state.write"))
+}
+
+// NewStateDelete returns the state_delete host function, which removes a
+// key
+func NewStateDelete() host.HostFunction {
+	return host.NewJSONFunc("state_delete", func(ctx *host.CallContext, req KeyReq) (string, error) {
+		store, contractID, err := contractState(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		return "", store.Delete(contractID, req.Key)
+	}, host.WithCapability("state.write"))
+}
+
+// NewStateHas returns the state_has host function, which responds with
+// true or false
+func NewStateHas() host.HostFunction {
+	return host.NewJSONFunc("state_has", func(ctx *host.CallContext, req KeyReq) (bool, error) {
+		store, contractID, err := contractState(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		return store.Has(contractID, req.Key)
+	}, host.WithCapability("state.read"))
+}
+
+// contractState returns the store and contract ID of the calling module
+func contractState(ctx *host.CallContext) (wasmState.StateStore, string, error) {
+	if ctx.WasmCtx == nil || ctx.WasmCtx.StateStore() == nil {
+		return nil, "", errors.New("contract has no state store")
+	}
+	return ctx.WasmCtx.StateStore(), ctx.WasmCtx.ContractID(), nil
+}
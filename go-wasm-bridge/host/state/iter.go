@@ -0,0 +1,110 @@
+package state
+
+import (
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host"
+	wasmState "github.com/rubixchain/rubix-wasm/go-wasm-bridge/state"
+)
+
+const (
+	// DefaultPageLimit is the number of entries in a page if the contract
+	// does not ask for a limit
+	DefaultPageLimit = 100
+
+	// MaxPageLimit caps the number of entries in a page
+	MaxPageLimit = 1000
+
+	// MaxPageBytes caps the total size of the keys and values in a page, so
+	// that a page always fits comfortably into guest memory. A page holds at
+	// least one entry, however large.
+	MaxPageBytes = 256 * 1024
+)
+
+// IterPrefixReq is the input of state_iter_prefix
+type IterPrefixReq struct {
+	Prefix string `json:"prefix"`
+	Cursor string `json:"cursor,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+// RangeReq is the input of state_range. An empty End means no upper bound.
+type RangeReq struct {
+	Start  string `json:"start"`
+	End    string `json:"end,omitempty"`
+	Cursor string `json:"cursor,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+// Entry is a key of the contract state with its value
+type Entry struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Page is the response of state_iter_prefix and state_range. NextCursor is
+// empty once the iteration is complete; otherwise it is passed back as the
+// cursor of the next request.
+type Page struct {
+	Entries    []Entry `json:"entries"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+// NewStateIterPrefix returns the state_iter_prefix host function, which
+// responds with a page of the keys starting with a prefix, in ascending
+// order
+func NewStateIterPrefix() host.HostFunction {
+	return host.NewJSONFunc("state_iter_prefix", func(ctx *host.CallContext, req IterPrefixReq) (Page, error) {
+		return readPage(ctx, req.Prefix, wasmState.PrefixEnd(req.Prefix), req.Cursor, req.Limit)
+	}, host.WithCapability("state.read"))
+}
+
+// NewStateRange returns the state_range host function, which responds with
+// a page of the keys in [start, end), in ascending order
+func NewStateRange() host.HostFunction {
+	return host.NewJSONFunc("state_range", func(ctx *host.CallContext, req RangeReq) (Page, error) {
+		if req.End != "" && req.End < req.Start {
+			return Page{}, host.NewGuestError(host.GuestErrorCode, "range end %q is before start %q", req.End, req.Start)
+		}
+		return readPage(ctx, req.Start, req.End, req.Cursor, req.Limit)
+	}, host.WithCapability("state.read"))
+}
+
+// readPage reads up to limit entries of [start, end), resuming at cursor.
+// The cursor is the first key of the page, so it must lie in the range.
+func readPage(ctx *host.CallContext, start, end, cursor string, limit int) (Page, error) {
+	store, contractID, err := contractState(ctx)
+	if err != nil {
+		return Page{}, err
+	}
+
+	switch {
+	case limit < 0:
+		return Page{}, host.NewGuestError(host.GuestErrorCode, "invalid page limit %d", limit)
+	case limit == 0:
+		limit = DefaultPageLimit
+	case limit > MaxPageLimit:
+		limit = MaxPageLimit
+	}
+
+	if cursor != "" {
+		if cursor < start || (end != "" && cursor >= end) {
+			return Page{}, host.NewGuestError(host.GuestErrorCode, "cursor %q is outside of the range", cursor)
+		}
+		start = cursor
+	}
+
+	page := Page{Entries: []Entry{}}
+	size := 0
+	err = store.Iterate(contractID, start, end, func(key string, value []byte) bool {
+		size += len(key) + len(value)
+		if len(page.Entries) == limit || (len(page.Entries) > 0 && size > MaxPageBytes) {
+			page.NextCursor = key
+			return false
+		}
+		page.Entries = append(page.Entries, Entry{Key: key, Value: string(value)})
+		return true
+	})
+	if err != nil {
+		return Page{}, err
+	}
+	return page, nil
+}
@@ -0,0 +1,141 @@
+package wasmbridge
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bytecodealliance/wasmtime-go"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host"
+)
+
+// ImportReport compares the imports of a contract with the host functions
+// of a HostFunctionRegistry. Imports are named "<module>.<name>".
+type ImportReport struct {
+	// Missing lists imports no registered host function provides
+	Missing []string
+	// SignatureMismatches lists imports whose type differs from the
+	// registered host function of the same name
+	SignatureMismatches []ImportMismatch
+	// Unused lists registered host functions the contract does not import
+	Unused []string
+}
+
+// ImportMismatch describes an import whose signature differs from the
+// registered host function
+type ImportMismatch struct {
+	Import   string
+	Expected string
+	Actual   string
+}
+
+// OK reports whether every import of the contract can be resolved.
+// Unused host functions do not prevent instantiation.
+func (r *ImportReport) OK() bool {
+	return len(r.Missing) == 0 && len(r.SignatureMismatches) == 0
+}
+
+func (r *ImportReport) String() string {
+	var problems []string
+	for _, missing := range r.Missing {
+		problems = append(problems, fmt.Sprintf("missing host function %v", missing))
+	}
+	for _, mismatch := range r.SignatureMismatches {
+		problems = append(problems, fmt.Sprintf(
+			"import %v has signature %v, host function has %v",
+			mismatch.Import, mismatch.Actual, mismatch.Expected,
+		))
+	}
+	for _, unused := range r.Unused {
+		problems = append(problems, fmt.Sprintf("unused host function %v", unused))
+	}
+	return strings.Join(problems, "; ")
+}
+
+// UnresolvedImportsError is returned at load time when the imports of a
+// contract cannot be resolved against the HostFunctionRegistry
+type UnresolvedImportsError struct {
+	Report *ImportReport
+}
+
+func (e *UnresolvedImportsError) Error() string {
+	unresolved := &ImportReport{
+		Missing:             e.Report.Missing,
+		SignatureMismatches: e.Report.SignatureMismatches,
+	}
+	return fmt.Sprintf("unresolved contract imports: %v", unresolved)
+}
+
+// CheckImports compiles the contract at wasmFilePath and reports how its
+// imports resolve against the registry
+func CheckImports(wasmFilePath string, registry *HostFunctionRegistry) (*ImportReport, error) {
+	wasmBytes, err := os.ReadFile(wasmFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	wasmBytes, err = toWasmBinary(wasmBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	module, err := wasmtime.NewModule(newEngine(), wasmBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkImports(module, registry), nil
+}
+
+func checkImports(module *wasmtime.Module, registry *HostFunctionRegistry) *ImportReport {
+	report := &ImportReport{}
+
+	registered := map[string]*wasmtime.FuncType{}
+	for _, hf := range registry.GetHostFunctions() {
+		registered[host.NamespaceOf(hf)+"."+hf.Name()] = hf.FuncType()
+	}
+
+	imported := map[string]bool{}
+	for _, imp := range module.Imports() {
+		name := imp.Module()
+		if imp.Name() != nil {
+			name += "." + *imp.Name()
+		}
+		imported[name] = true
+
+		expected, ok := registered[name]
+		if !ok {
+			report.Missing = append(report.Missing, name)
+			continue
+		}
+
+		actual := imp.Type().FuncType()
+		if actual == nil {
+			report.SignatureMismatches = append(report.SignatureMismatches, ImportMismatch{
+				Import:   name,
+				Expected: formatFuncType(expected),
+				Actual:   "non-function import",
+			})
+			continue
+		}
+
+		if !valKindsEqual(valKinds(expected.Params()), valKinds(actual.Params())) ||
+			!valKindsEqual(valKinds(expected.Results()), valKinds(actual.Results())) {
+			report.SignatureMismatches = append(report.SignatureMismatches, ImportMismatch{
+				Import:   name,
+				Expected: formatFuncType(expected),
+				Actual:   formatFuncType(actual),
+			})
+		}
+	}
+
+	for name := range registered {
+		if !imported[name] {
+			report.Unused = append(report.Unused, name)
+		}
+	}
+	sort.Strings(report.Unused)
+
+	return report
+}
@@ -0,0 +1,48 @@
+package wasmbridge
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUnresolvedImports(t *testing.T) {
+	contract := testContract(
+		hostImport("env", "no_such_function")+"\n"+
+			`(import "env" "do_api_call" (func $do_api_call (param i32) (result i32)))`,
+		okFunc,
+	)
+
+	_, err := NewWasmModule(writeTestContract(t, contract), NewHostFunctionRegistry())
+	var importsErr *UnresolvedImportsError
+	if !errors.As(err, &importsErr) {
+		t.Fatalf("expected an UnresolvedImportsError, got %v", err)
+	}
+
+	report := importsErr.Report
+	if len(report.Missing) != 1 || report.Missing[0] != "env.no_such_function" {
+		t.Errorf("unexpected missing imports %v", report.Missing)
+	}
+	if len(report.SignatureMismatches) != 1 || report.SignatureMismatches[0].Import != "env.do_api_call" {
+		t.Errorf("unexpected signature mismatches %+v", report.SignatureMismatches)
+	}
+}
+
+func TestCheckImports(t *testing.T) {
+	contractPath := writeTestContract(t, testContract(hostImport("env", "emit_event"), okFunc))
+
+	report, err := CheckImports(contractPath, NewHostFunctionRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() {
+		t.Fatalf("expected the imports to resolve: %v", report)
+	}
+
+	unused := map[string]bool{}
+	for _, name := range report.Unused {
+		unused[name] = true
+	}
+	if unused["env.emit_event"] || !unused["env.do_api_call"] {
+		t.Errorf("unexpected unused host functions %v", report.Unused)
+	}
+}
@@ -0,0 +1,68 @@
+package wasmbridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bytecodealliance/wasmtime-go"
+)
+
+// ErrSharedInterrupt is matched by the error of a call which was
+// interrupted because the context of another call on the same engine was
+// done: epoch interruption reaches every cancellable call running on the
+// engine, such as the other instances of a WasmPool. These calls are
+// retried, up to maxInterruptRetries times, unless they had already
+// invoked a host function, whose effects cannot be undone. Otherwise they
+// fail with a TrapError of kind TrapInterrupted wrapping ErrSharedInterrupt.
+var ErrSharedInterrupt = errors.New("interrupted along with another call on the engine")
+
+// maxInterruptRetries is the number of attempts of a call which keeps
+// being interrupted along with other calls
+const maxInterruptRetries = 3
+
+// noEpochDeadline is the epoch deadline of a store while no cancellable
+// call is running on it
+const noEpochDeadline uint64 = 1 << 62
+
+// watchContext arms epoch interruption so that guest code is interrupted
+// as soon as ctx is done. The returned function must be called once the
+// call has returned to stop watching ctx.
+func (w *WasmModule) watchContext(ctx context.Context) func() {
+	if ctx.Done() == nil {
+		w.store.SetEpochDeadline(noEpochDeadline)
+		return func() {}
+	}
+
+	// Any increment of the engine epoch now interrupts the guest
+	w.store.SetEpochDeadline(1)
+
+	finished := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			w.engine.IncrementEpoch()
+		case <-finished:
+		}
+	}()
+
+	return func() {
+		close(finished)
+		w.store.SetEpochDeadline(noEpochDeadline)
+	}
+}
+
+// contextError wraps the error of a cancelled or expired ctx
+func contextError(ctx context.Context) error {
+	return fmt.Errorf("contract execution interrupted: %w", ctx.Err())
+}
+
+// isInterrupt reports whether err is the trap of an epoch interruption
+func isInterrupt(err error) bool {
+	var trap *wasmtime.Trap
+	if !errors.As(err, &trap) {
+		return false
+	}
+	code := trap.Code()
+	return code != nil && *code == wasmtime.Interrupt
+}
@@ -0,0 +1,90 @@
+package wasmbridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// firstFunc returns "ok" with return code 0 on the first call of an
+// instance, and with return code 1 on every later call
+const firstFunc = `(global $called (mut i32) (i32.const 0))
+  (func (export "first_") (param i32 i32 i32 i32) (result i32)
+    local.get 2
+    i32.const 16
+    i32.store
+    local.get 3
+    i32.const 4
+    i32.store
+    global.get $called
+    i32.const 1
+    global.set $called)`
+
+func TestContextDeadlineInterruptsCall(t *testing.T) {
+	module := newTestModule(t, testContract("", spinFunc, okFunc))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if _, err := module.CallFunctionContext(ctx, `{"spin": {}}`); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestCancelledContextIsNotCalled(t *testing.T) {
+	module := newTestModule(t, testContract("", firstFunc))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := module.CallFunctionContext(ctx, `{"first": {}}`); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if _, err := module.CallFunction(`{"first": {}}`); err != nil {
+		t.Errorf("expected the cancelled call not to run the contract, got %v", err)
+	}
+}
+
+func TestCallsAfterInterruptRun(t *testing.T) {
+	module := newTestModule(t, testContract("", spinFunc, okFunc))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := module.CallFunctionContext(ctx, `{"spin": {}}`); err == nil {
+		t.Fatal("expected the spinning call to be interrupted")
+	}
+
+	// Calls with and without a cancellable context must not be affected
+	// by the epoch increment of the interrupted call
+	longCtx, cancelLong := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelLong()
+	if _, err := module.CallFunctionContext(longCtx, `{"ok": {}}`); err != nil {
+		t.Fatalf("call with context failed: %v", err)
+	}
+	if _, err := module.CallFunction(`{"ok": {}}`); err != nil {
+		t.Fatalf("call without context failed: %v", err)
+	}
+}
+
+func TestInterruptedContractIsInstantiatedAnew(t *testing.T) {
+	module := newTestModule(t, testContract("", spinFunc, firstFunc))
+
+	if _, err := module.CallFunction(`{"first": {}}`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := module.CallFunction(`{"first": {}}`); err == nil {
+		t.Fatal("expected the second call of the instance to fail")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := module.CallFunctionContext(ctx, `{"spin": {}}`); err == nil {
+		t.Fatal("expected the spinning call to be interrupted")
+	}
+
+	// The interrupted instance is replaced, which resets its globals
+	if _, err := module.CallFunction(`{"first": {}}`); err != nil {
+		t.Errorf("expected the contract to be instantiated anew, got %v", err)
+	}
+}
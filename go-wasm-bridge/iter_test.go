@@ -0,0 +1,115 @@
+package wasmbridge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host"
+	hostState "github.com/rubixchain/rubix-wasm/go-wasm-bridge/host/state"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/state"
+)
+
+// newItemStore returns a store with the keys item:1 to item:5 and other in
+// the state of the contract counter
+func newItemStore(t *testing.T) *state.MemoryStore {
+	t.Helper()
+
+	store := state.NewMemoryStore()
+	for i := 1; i <= 5; i++ {
+		if err := store.Set("counter", fmt.Sprintf("item:%d", i), []byte(fmt.Sprint(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Set("counter", "other", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+// readPages calls function until the returned cursor is empty, and
+// returns the keys of all pages
+func readPages(t *testing.T, module *WasmModule, responses *[]string, function string, req map[string]interface{}) (keys []string, pages int) {
+	t.Helper()
+
+	for {
+		input, err := json.Marshal(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := module.Call(function, input); err != nil {
+			t.Fatal(err)
+		}
+
+		var page hostState.Page
+		if err := json.Unmarshal([]byte(lastResponse(t, *responses)), &page); err != nil {
+			t.Fatal(err)
+		}
+		pages++
+		for _, entry := range page.Entries {
+			keys = append(keys, entry.Key)
+		}
+		if page.NextCursor == "" {
+			return keys, pages
+		}
+		req["cursor"] = page.NextCursor
+	}
+}
+
+func TestStateIterPrefixPages(t *testing.T) {
+	var responses []string
+	module := newStateModule(t, newItemStore(t), &responses)
+
+	keys, pages := readPages(t, module, &responses, "state_iter_prefix", map[string]interface{}{"prefix": "item:", "limit": 2})
+	expected := []string{"item:1", "item:2", "item:3", "item:4", "item:5"}
+	if !reflect.DeepEqual(keys, expected) || pages != 3 {
+		t.Errorf("expected %v in 3 pages, got %v in %d", expected, keys, pages)
+	}
+}
+
+func TestStateRangePages(t *testing.T) {
+	var responses []string
+	module := newStateModule(t, newItemStore(t), &responses)
+
+	keys, pages := readPages(t, module, &responses, "state_range", map[string]interface{}{"start": "item:2", "end": "item:5", "limit": 2})
+	expected := []string{"item:2", "item:3", "item:4"}
+	if !reflect.DeepEqual(keys, expected) || pages != 2 {
+		t.Errorf("expected %v in 2 pages, got %v in %d", expected, keys, pages)
+	}
+
+	// Without an end, the range runs to the last key
+	keys, _ = readPages(t, module, &responses, "state_range", map[string]interface{}{"start": "item:4"})
+	expected = []string{"item:4", "item:5", "other"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %v, got %v", expected, keys)
+	}
+}
+
+func TestStatePagesInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name     string
+		function string
+		input    string
+	}{
+		{"cursor before prefix", "state_iter_prefix", `{"prefix": "item:", "cursor": "a"}`},
+		{"cursor past prefix", "state_iter_prefix", `{"prefix": "item:", "cursor": "other"}`},
+		{"cursor at range end", "state_range", `{"start": "item:1", "end": "item:3", "cursor": "item:3"}`},
+		{"negative limit", "state_range", `{"start": "item:1", "limit": -1}`},
+		{"end before start", "state_range", `{"start": "item:3", "end": "item:1"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var responses []string
+			module := newStateModule(t, newItemStore(t), &responses)
+
+			_, err := module.Call(tt.function, []byte(tt.input))
+			var contractErr *ContractError
+			if !errors.As(err, &contractErr) || contractErr.Code != host.GuestErrorCode {
+				t.Fatalf("expected a ContractError with code %d, got %v", host.GuestErrorCode, err)
+			}
+		})
+	}
+}
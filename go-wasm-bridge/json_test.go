@@ -0,0 +1,131 @@
+package wasmbridge
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host"
+)
+
+// pointerCallFunc returns the contract function <export>_, which calls the
+// host function $<name> with the given input pointer and length
+func pointerCallFunc(export, name string, ptr, length int32) string {
+	return fmt.Sprintf(`(func (export "%s_") (param i32 i32 i32 i32) (result i32)
+    i32.const %d
+    i32.const %d
+    local.get 2
+    local.get 3
+    call $%s)`, export, ptr, length, name)
+}
+
+// passFunc returns the contract function <name>_, which returns the
+// response of the host function $<name> as its output
+func passFunc(name string) string {
+	return fmt.Sprintf(`(func (export "%s_") (param i32 i32 i32 i32) (result i32)
+    local.get 0
+    local.get 1
+    local.get 2
+    local.get 3
+    call $%s)`, name, name)
+}
+
+// badOutputFunc returns a negative output length
+const badOutputFunc = `(func (export "badoutput_") (param i32 i32 i32 i32) (result i32)
+    local.get 2
+    i32.const 100
+    i32.store
+    local.get 3
+    i32.const -50
+    i32.store
+    i32.const 0)`
+
+type addReq struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+var errNegative = errors.New("negative operand")
+
+// newAddFunc returns the host function add, which sums its operands. The
+// string response is passed through as is, so it is quoted here to be a
+// valid contract output.
+func newAddFunc() host.HostFunction {
+	return host.NewJSONFunc("add", func(ctx *host.CallContext, req addReq) (string, error) {
+		if req.A < 0 || req.B < 0 {
+			return "", errNegative
+		}
+		return fmt.Sprintf(`"%d"`, req.A+req.B), nil
+	})
+}
+
+func TestJSONFunc(t *testing.T) {
+	registry := NewHostFunctionRegistry()
+	registry.Register(newAddFunc())
+
+	module, err := NewWasmModuleFromBytes(testContract(hostImport("env", "add"), passFunc("add")), registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := module.Call("add", []byte(`{"a": 1, "b": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "3" {
+		t.Errorf("expected output 3, got %q", result.Output)
+	}
+
+	// Input which does not decode into the request traps the contract
+	var hostErr *HostFunctionError
+	if _, err := module.Call("add", []byte(`"not an object"`)); !errors.As(err, &hostErr) {
+		t.Errorf("expected a HostFunctionError, got %v", err)
+	}
+}
+
+func TestJSONFuncHandlerError(t *testing.T) {
+	registry := NewHostFunctionRegistry()
+	registry.Register(newAddFunc())
+
+	module, err := NewWasmModuleFromBytes(testContract(hostImport("env", "add"), passFunc("add")), registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The error returned by the handler is the cause of the failed call
+	_, err = module.Call("add", []byte(`{"a": -1, "b": 2}`))
+	var hostErr *HostFunctionError
+	if !errors.As(err, &hostErr) || hostErr.Function != "add" {
+		t.Fatalf("expected a HostFunctionError from add, got %v", err)
+	}
+	if !errors.Is(err, errNegative) {
+		t.Errorf("expected the handler error, got %v", hostErr.Err)
+	}
+}
+
+func TestJSONFuncRejectsBadPointers(t *testing.T) {
+	contract := testContract(
+		hostImport("env", "do_api_call"),
+		pointerCallFunc("negativelen", "do_api_call", 100, -50),
+		pointerCallFunc("negativeptr", "do_api_call", -100, 10),
+		pointerCallFunc("outofrange", "do_api_call", 0, 3*65536),
+		pointerCallFunc("overflow", "do_api_call", 0x7fffffff, 0x7fffffff),
+	)
+	module := newTestModule(t, contract)
+
+	for _, funcName := range []string{"negativelen", "negativeptr", "outofrange", "overflow"} {
+		_, err := module.Call(funcName, []byte(`{}`))
+		var hostErr *HostFunctionError
+		if !errors.As(err, &hostErr) {
+			t.Errorf("%s: expected a HostFunctionError, got %v", funcName, err)
+		}
+	}
+}
+
+func TestContractOutputRejectsBadPointers(t *testing.T) {
+	module := newTestModule(t, testContract("", badOutputFunc))
+
+	var outputErr *OutputDecodeError
+	if _, err := module.Call("badoutput", []byte(`{}`)); !errors.As(err, &outputErr) {
+		t.Fatalf("expected an OutputDecodeError, got %v", err)
+	}
+}
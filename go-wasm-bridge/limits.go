@@ -0,0 +1,230 @@
+package wasmbridge
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bytecodealliance/wasmtime-go"
+)
+
+// ErrResourceLimit is matched by errors returned when a contract exceeds
+// one of the configured resource limits
+var ErrResourceLimit = errors.New("resource limit exceeded")
+
+// Limited resources
+const (
+	ResourceMemoryPages   = "memory_pages"
+	ResourceTableElements = "table_elements"
+	ResourceInstances     = "instances"
+)
+
+// ResourceLimitError is returned when a contract does not fit in a
+// configured resource limit at load time
+type ResourceLimitError struct {
+	Resource string
+	Limit    uint64
+	Message  string
+}
+
+func (e *ResourceLimitError) Error() string {
+	return fmt.Sprintf("%v: %v limit of %d: %v", ErrResourceLimit, e.Resource, e.Limit, e.Message)
+}
+
+func (e *ResourceLimitError) Is(target error) bool {
+	return target == ErrResourceLimit
+}
+
+type resourceLimits struct {
+	maxMemoryPages   uint32
+	maxTableElements uint32
+	maxInstances     int
+}
+
+// WithMaxMemoryPages caps the linear memories of the contract at pages
+// 64KiB WASM pages. Contracts are rejected at load time unless every
+// memory declares a maximum within the cap, which the engine enforces on
+// memory.grow.
+func WithMaxMemoryPages(pages uint32) WasmModuleOption {
+	return func(w *WasmModule) {
+		w.limits.maxMemoryPages = pages
+	}
+}
+
+// WithMaxTableElements caps the tables of the contract at elements
+// entries. Contracts are rejected at load time unless every table declares
+// a maximum within the cap, which the engine enforces on table.grow.
+func WithMaxTableElements(elements uint32) WasmModuleOption {
+	return func(w *WasmModule) {
+		w.limits.maxTableElements = elements
+	}
+}
+
+// WithMaxInstances caps the number of live instances of the contract,
+// which bounds the size of a WasmPool
+func WithMaxInstances(instances int) WasmModuleOption {
+	return func(w *WasmModule) {
+		w.limits.maxInstances = instances
+	}
+}
+
+// atMemoryLimit reports whether the linear memory has grown to its
+// declared maximum under a memory cap, in which case a trap is attributed
+// to a denied memory.grow
+func (w *WasmModule) atMemoryLimit() bool {
+	if w.limits.maxMemoryPages == 0 {
+		return false
+	}
+	hasMax, max := w.memory.Type(w.store).Maximum()
+	return hasMax && w.memory.Size(w.store) >= max
+}
+
+// WASM binary encoding, see https://webassembly.github.io/spec/core/binary/
+const (
+	wasmHeaderSize = 8
+
+	tableSectionID  = 4
+	memorySectionID = 5
+
+	limitsHasMax = 0x01
+)
+
+// check verifies that every memory and table of the module, imported or
+// defined, declares a maximum within the limits. wasmtime-go v1.0.0 does
+// not expose store limits, so the maxima declared by the contract are what
+// the engine enforces.
+func (l resourceLimits) check(wasmBytes []byte, module *wasmtime.Module) error {
+	if l.maxMemoryPages == 0 && l.maxTableElements == 0 {
+		return nil
+	}
+
+	// Imported memories and tables are described by the module
+	for _, imp := range module.Imports() {
+		name := imp.Module()
+		if imp.Name() != nil {
+			name += "." + *imp.Name()
+		}
+
+		if memoryType := imp.Type().MemoryType(); memoryType != nil && l.maxMemoryPages != 0 {
+			hasMax, max := memoryType.Maximum()
+			if err := checkLimit(ResourceMemoryPages, name, uint64(l.maxMemoryPages), memoryType.Minimum(), hasMax, max); err != nil {
+				return err
+			}
+		}
+		if tableType := imp.Type().TableType(); tableType != nil && l.maxTableElements != 0 {
+			hasMax, max := tableType.Maximum()
+			if err := checkLimit(ResourceTableElements, name, uint64(l.maxTableElements), uint64(tableType.Minimum()), hasMax, uint64(max)); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Defined memories and tables are read from the binary, as the module
+	// only describes those which are exported
+	if len(wasmBytes) < wasmHeaderSize {
+		return errors.New("invalid WASM binary: missing header")
+	}
+	rest := wasmBytes[wasmHeaderSize:]
+
+	for len(rest) > 0 {
+		sectionID := rest[0]
+		size, n, err := readULEB(rest[1:])
+		if err != nil || uint64(len(rest)-1-n) < size {
+			return errors.New("invalid WASM binary: malformed section")
+		}
+		contents := rest[1+n : 1+n+int(size)]
+		rest = rest[1+n+int(size):]
+
+		switch {
+		case sectionID == memorySectionID && l.maxMemoryPages != 0:
+			err = checkSection(contents, 0, uint64(l.maxMemoryPages), ResourceMemoryPages)
+		case sectionID == tableSectionID && l.maxTableElements != 0:
+			// Every table type is prefixed with its reference type
+			err = checkSection(contents, 1, uint64(l.maxTableElements), ResourceTableElements)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkSection checks the limits of every entry of a table or memory
+// section. prefixLen is the number of bytes preceding the limits of an entry.
+func checkSection(section []byte, prefixLen int, limit uint64, resource string) error {
+	malformed := fmt.Errorf("invalid WASM binary: malformed %v section", resource)
+
+	count, n, err := readULEB(section)
+	if err != nil {
+		return malformed
+	}
+
+	rest := section[n:]
+	for i := uint64(0); i < count; i++ {
+		if len(rest) < prefixLen+1 {
+			return malformed
+		}
+		flags := rest[prefixLen]
+		rest = rest[prefixLen+1:]
+
+		minimum, n, err := readULEB(rest)
+		if err != nil {
+			return malformed
+		}
+		rest = rest[n:]
+
+		var maximum uint64
+		hasMax := flags&limitsHasMax != 0
+		if hasMax {
+			maximum, n, err = readULEB(rest)
+			if err != nil {
+				return malformed
+			}
+			rest = rest[n:]
+		}
+
+		if err := checkLimit(resource, fmt.Sprintf("#%d", i), limit, minimum, hasMax, maximum); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkLimit checks the limits declared for a memory or table
+func checkLimit(resource, name string, limit, minimum uint64, hasMax bool, maximum uint64) error {
+	switch {
+	case minimum > limit:
+		return &ResourceLimitError{
+			Resource: resource,
+			Limit:    limit,
+			Message:  fmt.Sprintf("%v requires at least %d", name, minimum),
+		}
+	case !hasMax:
+		return &ResourceLimitError{
+			Resource: resource,
+			Limit:    limit,
+			Message:  fmt.Sprintf("%v declares no maximum", name),
+		}
+	case maximum > limit:
+		return &ResourceLimitError{
+			Resource: resource,
+			Limit:    limit,
+			Message:  fmt.Sprintf("%v declares a maximum of %d", name, maximum),
+		}
+	}
+	return nil
+}
+
+// readULEB decodes an unsigned LEB128 integer and returns it along with
+// the number of bytes read
+func readULEB(b []byte) (uint64, int, error) {
+	var value uint64
+	for i := 0; i < len(b) && i < 10; i++ {
+		value |= uint64(b[i]&0x7f) << (7 * i)
+		if b[i]&0x80 == 0 {
+			return value, i + 1, nil
+		}
+	}
+	return 0, 0, errors.New("malformed LEB128 integer")
+}
@@ -0,0 +1,95 @@
+package wasmbridge
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// growFunc grows the memory page by page until memory.grow fails, and
+// aborts like a contract whose allocator is denied memory
+const growFunc = `(func (export "grow_") (param i32 i32 i32 i32) (result i32)
+    (loop
+      i32.const 1
+      memory.grow
+      i32.const -1
+      i32.ne
+      br_if 0)
+    unreachable)`
+
+// limitedContract returns a test contract whose memory declares the limits
+// memoryLimits, with an additional table tableDecl
+func limitedContract(memoryLimits, tableDecl string, funcs ...string) []byte {
+	contract := string(testContract(tableDecl, funcs...))
+	return []byte(strings.Replace(contract, `(memory (export "memory") 2)`, `(memory (export "memory") `+memoryLimits+`)`, 1))
+}
+
+func TestMaxInstancesCapsPoolSize(t *testing.T) {
+	contractPath := writeTestContract(t, testContract("", okFunc))
+
+	_, err := NewWasmPool(contractPath, nil, 3, WithMaxInstances(2))
+	var limitErr *ResourceLimitError
+	if !errors.As(err, &limitErr) || limitErr.Resource != ResourceInstances || limitErr.Limit != 2 {
+		t.Fatalf("expected an instances ResourceLimitError, got %v", err)
+	}
+	if !errors.Is(err, ErrResourceLimit) {
+		t.Errorf("expected the error to match ErrResourceLimit")
+	}
+
+	pool, err := NewWasmPool(contractPath, nil, 2, WithMaxInstances(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pool.Size() != 2 {
+		t.Errorf("expected a pool of 2 instances, got %d", pool.Size())
+	}
+}
+
+func TestLoadTimeResourceLimits(t *testing.T) {
+	for name, test := range map[string]struct {
+		contract []byte
+		resource string
+	}{
+		"memory without maximum":   {limitedContract("2", "", okFunc), ResourceMemoryPages},
+		"memory maximum above cap": {limitedContract("2 16", "", okFunc), ResourceMemoryPages},
+		"memory minimum above cap": {limitedContract("8 8", "", okFunc), ResourceMemoryPages},
+		"table without maximum":    {limitedContract("2 4", "(table 1 funcref)", okFunc), ResourceTableElements},
+		"table maximum above cap":  {limitedContract("2 4", "(table 1 64 funcref)", okFunc), ResourceTableElements},
+		"imported memory without maximum": {
+			[]byte(`(module (import "env" "memory" (memory 1)))`),
+			ResourceMemoryPages,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewWasmModule(writeTestContract(t, test.contract), NewHostFunctionRegistry(),
+				WithMaxMemoryPages(4), WithMaxTableElements(8))
+			var limitErr *ResourceLimitError
+			if !errors.As(err, &limitErr) || limitErr.Resource != test.resource {
+				t.Fatalf("expected a %v ResourceLimitError, got %v", test.resource, err)
+			}
+		})
+	}
+
+	module := newTestModule(t, limitedContract("2 4", "(table 1 8 funcref)", okFunc),
+		WithMaxMemoryPages(4), WithMaxTableElements(8))
+	if _, err := module.CallFunction(`{"ok": {}}`); err != nil {
+		t.Errorf("expected a contract within the limits to run, got %v", err)
+	}
+}
+
+func TestDeniedMemoryGrowth(t *testing.T) {
+	module := newTestModule(t, limitedContract("2 4", "", growFunc, okFunc), WithMaxMemoryPages(4))
+
+	_, err := module.CallFunction(`{"grow": {}}`)
+	var trapErr *TrapError
+	if !errors.As(err, &trapErr) || trapErr.Kind != TrapResourceLimit {
+		t.Fatalf("expected a resource limit TrapError, got %v", err)
+	}
+	if !errors.Is(err, ErrResourceLimit) {
+		t.Errorf("expected the error to match ErrResourceLimit")
+	}
+
+	if _, err := module.CallFunction(`{"ok": {}}`); err != nil {
+		t.Errorf("expected the contract to recover from the denied growth, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package wasmbridge
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bytecodealliance/wasmtime-go"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	registry := NewHostFunctionRegistry()
+	var trace []string
+	for _, name := range []string{"outer", "inner"} {
+		name := name
+		registry.Use(func(next host.Handler) host.Handler {
+			return func(inv *host.Invocation) ([]wasmtime.Val, *wasmtime.Trap) {
+				trace = append(trace, name+">"+inv.Namespace+"."+inv.Name+":"+string(inv.Input()))
+				results, trap := next(inv)
+				trace = append(trace, name+"<")
+				return results, trap
+			}
+		})
+	}
+
+	module, err := NewWasmModuleFromBytes(emitContract, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := module.Call("emit_event", []byte(`{"name":"bid"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `outer>env.emit_event:{"name":"bid"} inner>env.emit_event:{"name":"bid"} inner< outer<`
+	if strings.Join(trace, " ") != expected {
+		t.Errorf("expected trace %v, got %v", expected, strings.Join(trace, " "))
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	registry := NewHostFunctionRegistry()
+	registry.Use(func(next host.Handler) host.Handler {
+		return func(inv *host.Invocation) ([]wasmtime.Val, *wasmtime.Trap) {
+			return []wasmtime.Val{wasmtime.ValI32(7)}, nil
+		}
+	})
+
+	module, err := NewWasmModuleFromBytes(emitContract, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := module.Call("emit_event", []byte(`{"name":"bid"}`))
+	if result == nil || result.ReturnCode != 7 {
+		t.Fatalf("expected return code 7 from the middleware, got %+v, %v", result, err)
+	}
+	if len(result.Events) != 0 {
+		t.Errorf("expected the host function not to run, got events %+v", result.Events)
+	}
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	registry := NewHostFunctionRegistry()
+	registry.Use(host.Recover(), func(next host.Handler) host.Handler {
+		return func(inv *host.Invocation) ([]wasmtime.Val, *wasmtime.Trap) {
+			panic("boom")
+		}
+	})
+
+	module, err := NewWasmModuleFromBytes(emitContract, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = module.Call("emit_event", []byte(`{"name":"bid"}`))
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected the panic to trap the contract, got %v", err)
+	}
+}
+
+func TestInvocationInputRejectsBadPointers(t *testing.T) {
+	registry := NewHostFunctionRegistry()
+	inputs := 0
+	registry.Use(func(next host.Handler) host.Handler {
+		return func(inv *host.Invocation) ([]wasmtime.Val, *wasmtime.Trap) {
+			if inv.Input() != nil {
+				inputs++
+			}
+			return next(inv)
+		}
+	})
+
+	contract := testContract(hostImport("env", "emit_event"), pointerCallFunc("negativelen", "emit_event", 100, -50))
+	module, err := NewWasmModuleFromBytes(contract, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := module.Call("negativelen", []byte(`{}`)); err == nil {
+		t.Error("expected the call to fail")
+	}
+	if inputs != 0 {
+		t.Error("expected no input for a negative length")
+	}
+}
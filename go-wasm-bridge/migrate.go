@@ -0,0 +1,79 @@
+package wasmbridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/state"
+)
+
+// MigrateFunction is the contract function that migrates state to the
+// layout of a new contract version. It is exported as migrate_ like every
+// contract function, receives a MigrationInput, and returns the new state
+// entries as a JSON object of strings.
+const MigrateFunction = "migrate"
+
+// MigrationInput is the input of the migrate function of a contract
+type MigrationInput struct {
+	ContractID string            `json:"contract_id"`
+	Entries    map[string]string `json:"entries"`
+}
+
+// MigrationResult describes a completed state migration
+type MigrationResult struct {
+	// Changes lists the writes that turned the old state into the new one
+	Changes []state.Change
+
+	// StateRoot is the root hash of the migrated state
+	StateRoot string
+
+	// Call is the result of the migrate function call
+	Call *CallResult
+}
+
+// Migrate rewrites the state of the contract stored under fromContractID
+// with the migrate function of this module, and swaps the result in as the
+// state of this module's contract ID. fromContractID may equal the
+// module's contract ID. The state of fromContractID is otherwise left in
+// place.
+//
+// The migrate function sees the old state only through its input: the
+// state host functions are unavailable while it runs. The swap is applied
+// atomically if the state store is a state.BatchStore. Other calls of the
+// contract should not run during a migration.
+func (w *WasmModule) Migrate(ctx context.Context, fromContractID string) (*MigrationResult, error) {
+	if w.stateStore == nil {
+		return nil, errors.New("contract has no state store")
+	}
+	if !slices.Contains(w.functions, MigrateFunction) {
+		return nil, fmt.Errorf("contract does not export a %s function", MigrateFunction)
+	}
+
+	snapshot, err := state.NewSnapshot(w.stateStore, fromContractID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state of contract %v: %w", fromContractID, err)
+	}
+
+	// Detach the state store for the duration of the call
+	w.wasmCtx.WithStateStore(nil)
+	entries, call, err := CallTyped[MigrationInput, map[string]string](ctx, w, MigrateFunction, MigrationInput{
+		ContractID: fromContractID,
+		Entries:    snapshot.Entries,
+	})
+	w.wasmCtx.WithStateStore(w.stateStore)
+	if err != nil {
+		return nil, err
+	}
+
+	migrated := state.Snapshot{ContractID: w.contractID, Entries: entries}
+	changes, err := migrated.Restore(w.stateStore, w.contractID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to swap in migrated state: %w", err)
+	}
+
+	result := &MigrationResult{Changes: changes, Call: call}
+	result.StateRoot, _ = w.StateRoot()
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package wasmbridge
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host"
+)
+
+func TestHostFunctionNamespaces(t *testing.T) {
+	registry := NewHostFunctionRegistry()
+	var called []string
+	for _, namespace := range []string{host.DefaultNamespace, "rubix"} {
+		namespace := namespace
+		err := registry.Register(host.NewJSONFunc("add", func(ctx *host.CallContext, req addReq) (string, error) {
+			called = append(called, namespace)
+			return fmt.Sprintf(`"%d"`, req.A+req.B), nil
+		}, host.WithNamespace(namespace)))
+		if err != nil {
+			t.Fatalf("failed to register add in %v: %v", namespace, err)
+		}
+	}
+
+	contract := testContract(
+		`(import "rubix" "add" (func $add (param i32 i32 i32 i32) (result i32)))`,
+		hostCallFunc("add"),
+	)
+	module, err := NewWasmModuleFromBytes(contract, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := module.Call("add", []byte(`{"a": 1, "b": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(called) != 1 || called[0] != "rubix" {
+		t.Errorf("expected the rubix host function to be called, got %v", called)
+	}
+	if len(result.HostCalls) != 1 || result.HostCalls[0] != "rubix.add" {
+		t.Errorf("expected host calls [rubix.add], got %v", result.HostCalls)
+	}
+
+	if _, ok := registry.LookupNamespaced("rubix", "add"); !ok {
+		t.Error("expected rubix.add to be registered")
+	}
+	if !registry.UnregisterNamespaced("rubix", "add") {
+		t.Error("expected rubix.add to be unregistered")
+	}
+	if _, ok := registry.Lookup("add"); !ok {
+		t.Error("expected env.add to remain registered")
+	}
+	if _, err := NewWasmModuleFromBytes(contract, registry); err == nil {
+		t.Error("expected the contract to fail to load without rubix.add")
+	}
+}
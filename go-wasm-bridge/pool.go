@@ -0,0 +1,167 @@
+package wasmbridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/bytecodealliance/wasmtime-go"
+)
+
+// WasmPool executes contract calls concurrently. The contract is compiled
+// once and instantiated in a fixed number of stores, each with its own
+// host function bindings. Callers block while every instance is busy.
+//
+// All instances share one engine, so cancelling a call through its context
+// also interrupts the other context-aware calls running at that moment.
+// These are retried transparently unless they had invoked a host function
+// already, in which case they fail with ErrSharedInterrupt.
+type WasmPool struct {
+	engine     *wasmtime.Engine
+	module     *wasmtime.Module
+	registry   *HostFunctionRegistry
+	moduleOpts []WasmModuleOption
+	contractID string
+
+	size      int
+	instances chan *WasmModule
+}
+
+// NewWasmPool compiles the contract (WASM or WAT) at wasmFilePath and
+// pre-instantiates size instances of it, each with its own host function
+// instances from registry. If registry is nil, NewHostFunctionRegistry is
+// used.
+func NewWasmPool(wasmFilePath string, registry *HostFunctionRegistry, size int, wasmModuleOpts ...WasmModuleOption) (*WasmPool, error) {
+	// Read the WASM file
+	wasmBytes, err := os.ReadFile(wasmFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWasmPoolFromBytes(wasmBytes, registry, size, wasmModuleOpts...)
+}
+
+// NewWasmPoolFromBytes is like NewWasmPool, but takes the contract itself,
+// either as a WASM binary or as WAT text
+func NewWasmPoolFromBytes(wasmBytes []byte, registry *HostFunctionRegistry, size int, wasmModuleOpts ...WasmModuleOption) (*WasmPool, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("pool size must be at least 1, got %d", size)
+	}
+	if registry == nil {
+		registry = NewHostFunctionRegistry()
+	}
+
+	moduleOpts := append([]WasmModuleOption{}, wasmModuleOpts...)
+
+	compiler := newWasmModule(nil, moduleOpts...)
+	if max := compiler.limits.maxInstances; max > 0 && size > max {
+		return nil, &ResourceLimitError{
+			Resource: ResourceInstances,
+			Limit:    uint64(max),
+			Message:  fmt.Sprintf("pool of %d instances requested", size),
+		}
+	}
+
+	if err := compiler.compile(wasmBytes); err != nil {
+		return nil, err
+	}
+
+	pool := &WasmPool{
+		engine:     compiler.engine,
+		module:     compiler.module,
+		registry:   registry,
+		moduleOpts: moduleOpts,
+		contractID: compiler.contractID,
+		size:       size,
+		instances:  make(chan *WasmModule, size),
+	}
+
+	for i := 0; i < size; i++ {
+		instance, err := pool.newInstance()
+		if err != nil {
+			return nil, fmt.Errorf("failed to instantiate pool member %d: %w", i, err)
+		}
+		pool.instances <- instance
+	}
+
+	return pool, nil
+}
+
+func (p *WasmPool) newInstance() (*WasmModule, error) {
+	instance := newWasmModule(p.registry, p.moduleOpts...)
+	instance.engine = p.engine
+	instance.module = p.module
+	instance.contractID = p.contractID
+	if err := instance.instantiate(); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// Size returns the number of instances in the pool
+func (p *WasmPool) Size() int {
+	return p.size
+}
+
+// Acquire takes an idle instance out of the pool, waiting until one is
+// released or ctx is done. The instance must be handed back with Release.
+func (p *WasmPool) Acquire(ctx context.Context) (*WasmModule, error) {
+	select {
+	case instance := <-p.instances:
+		return instance, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("no idle WASM instance available: %w", ctx.Err())
+	}
+}
+
+// Release returns an instance to the pool. An instance whose guest code
+// has trapped is instantiated anew first.
+func (p *WasmPool) Release(instance *WasmModule) error {
+	defer func() { p.instances <- instance }()
+	if !instance.trapped {
+		return nil
+	}
+
+	// On failure the instance stays trapped and is retried on its next call
+	if err := instance.instantiate(); err != nil {
+		return errors.Join(errors.New("failed to re-instantiate trapped WASM instance"), err)
+	}
+	return nil
+}
+
+// CallFunction invokes the exported WASM function on an idle instance
+func (p *WasmPool) CallFunction(args string, callOpts ...CallOption) (string, error) {
+	return p.CallFunctionContext(context.Background(), args, callOpts...)
+}
+
+// CallFunctionContext invokes the exported WASM function on an idle
+// instance. ctx bounds both the wait for an instance and the call itself.
+func (p *WasmPool) CallFunctionContext(ctx context.Context, args string, callOpts ...CallOption) (string, error) {
+	instance, err := p.Acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer p.Release(instance)
+
+	return instance.CallFunctionContext(ctx, args, callOpts...)
+}
+
+// Call invokes the contract function funcName with a raw JSON payload on
+// an idle instance
+func (p *WasmPool) Call(funcName string, payload json.RawMessage, callOpts ...CallOption) (*CallResult, error) {
+	return p.CallContext(context.Background(), funcName, payload, callOpts...)
+}
+
+// CallContext is like Call, but ctx bounds both the wait for an instance
+// and the call itself
+func (p *WasmPool) CallContext(ctx context.Context, funcName string, payload json.RawMessage, callOpts ...CallOption) (*CallResult, error) {
+	instance, err := p.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.Release(instance)
+
+	return instance.CallContext(ctx, funcName, payload, callOpts...)
+}
@@ -0,0 +1,90 @@
+package wasmbridge
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPoolRunsCallsConcurrently(t *testing.T) {
+	pool := newTestPool(t, testContract("", okFunc), 4)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 32)
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			output, err := pool.CallFunction(`{"ok": {}}`)
+			if err == nil && output != "ok" {
+				err = errors.New("unexpected output " + output)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestPoolCancellationSparesOtherCalls(t *testing.T) {
+	pool := newTestPool(t, testContract("", spinFunc, slowFunc), 2)
+
+	slowDone := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		_, err := pool.CallFunctionContext(ctx, `{"slow": {}}`)
+		slowDone <- err
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := pool.CallFunctionContext(ctx, `{"spin": {}}`); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	if err := <-slowDone; err != nil {
+		t.Errorf("expected the concurrent call to complete, got %v", err)
+	}
+}
+
+func TestPoolReplacesTrappedInstances(t *testing.T) {
+	pool := newTestPool(t, testContract("", trapFunc, okFunc), 1)
+
+	if _, err := pool.CallFunction(`{"trap": {}}`); err == nil {
+		t.Fatal("expected the call to trap")
+	}
+	if output, err := pool.CallFunction(`{"ok": {}}`); err != nil || output != "ok" {
+		t.Errorf("expected the pool to recover from the trap, got %q, %v", output, err)
+	}
+}
+
+func TestPoolAcquireWaitsForIdleInstance(t *testing.T) {
+	pool := newTestPool(t, testContract("", okFunc), 1)
+
+	instance, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected Acquire to wait for the busy instance, got %v", err)
+	}
+
+	if err := pool.Release(instance); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Acquire(context.Background()); err != nil {
+		t.Errorf("expected the released instance to be acquired, got %v", err)
+	}
+}
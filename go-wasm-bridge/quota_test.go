@@ -0,0 +1,88 @@
+package wasmbridge
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host"
+	hostState "github.com/rubixchain/rubix-wasm/go-wasm-bridge/host/state"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/state"
+)
+
+// expectUsage checks the state usage of the contract of module
+func expectUsage(t *testing.T, module *WasmModule, expected state.Usage) {
+	t.Helper()
+
+	usage, err := module.StateUsage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage != expected {
+		t.Errorf("expected usage %+v, got %+v", expected, usage)
+	}
+}
+
+func TestStateQuotaGuestError(t *testing.T) {
+	store := state.NewQuotaStore(state.NewMemoryStore(), state.Quota{MaxKeys: 2})
+	var responses []string
+	module := newStateModule(t, store, &responses)
+
+	for _, input := range []string{`{"key": "a", "value": "1"}`, `{"key": "b", "value": "2"}`} {
+		if _, err := module.Call("state_set", []byte(input)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := module.Call("state_set", []byte(`{"key": "c", "value": "3"}`))
+	var contractErr *ContractError
+	if !errors.As(err, &contractErr) || contractErr.Code != hostState.CodeQuotaExceeded {
+		t.Fatalf("expected a ContractError with code %d, got %v", hostState.CodeQuotaExceeded, err)
+	}
+	var guestErr host.GuestError
+	if err := json.Unmarshal([]byte(lastResponse(t, responses)), &guestErr); err != nil || guestErr.Code != hostState.CodeQuotaExceeded {
+		t.Errorf("unexpected error payload %s", lastResponse(t, responses))
+	}
+	if has, _ := store.Has("counter", "c"); has {
+		t.Error("expected the write over quota to be rejected")
+	}
+	expectUsage(t, module, state.Usage{Bytes: 4, Keys: 2})
+
+	// Overwriting a key keeps the key count
+	if _, err := module.Call("state_set", []byte(`{"key": "a", "value": "10"}`)); err != nil {
+		t.Fatal(err)
+	}
+	expectUsage(t, module, state.Usage{Bytes: 5, Keys: 2})
+}
+
+func TestStateUsageAfterRestore(t *testing.T) {
+	versions := state.NewVersionedStore(state.NewMemoryStore(), state.RetentionPolicy{})
+	store := state.NewQuotaStore(versions, state.Quota{MaxKeys: 2})
+	var responses []string
+	module := newStateModule(t, store, &responses)
+
+	set := func(input string) error {
+		_, err := module.Call("state_set", []byte(input))
+		return err
+	}
+	if err := set(`{"key": "a", "value": "1"}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := set(`{"key": "b", "value": "2"}`); err != nil {
+		t.Fatal(err)
+	}
+
+	// The restore writes to the VersionedStore beneath the QuotaStore
+	if _, err := module.RestoreState(0); err != nil {
+		t.Fatal(err)
+	}
+	expectUsage(t, module, state.Usage{})
+
+	if err := set(`{"key": "c", "value": "3"}`); err != nil {
+		t.Fatalf("expected the restored state to be within quota, got %v", err)
+	}
+	if err := set(`{"key": "d", "value": "4"}`); err != nil {
+		t.Fatal(err)
+	}
+	expectUsage(t, module, state.Usage{Bytes: 4, Keys: 2})
+}
@@ -1,39 +1,206 @@
 package wasmbridge
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
 	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host/events"
 	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host/ft"
 	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host/generic"
 	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host/nft"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host/state"
 )
 
-// HostFunctionRegistry manages the registration of host functions.
+// ErrDuplicateHostFunction is returned when a host function is registered
+// under a name which is already taken
+var ErrDuplicateHostFunction = errors.New("host function already registered")
+
+// HostFunctionRegistry manages the registration of host functions. Every
+// WasmModule instantiated with the registry gets its own host function
+// instances, so a registry can be shared between modules.
 type HostFunctionRegistry struct {
-	hostFunctions []host.HostFunction
+	mu          sync.RWMutex
+	entries     []registryEntry
+	middlewares []host.Middleware
+}
+
+// registryEntry holds a registered host function. prototype describes the
+// host function and factory creates the instances bound to modules.
+type registryEntry struct {
+	prototype host.HostFunction
+	factory   host.HostFunctionFactory
 }
 
 // NewHostFunctionRegistry creates a new registry with predefined host functions.
 func NewHostFunctionRegistry() *HostFunctionRegistry {
-	registry := &HostFunctionRegistry{
-		hostFunctions: []host.HostFunction{},
-	}
+	registry := &HostFunctionRegistry{}
 
 	// Register predefined host functions
-	registry.Register(generic.NewDoApiCall())
-	registry.Register(nft.NewDoMintNFTApiCall())
-	registry.Register(nft.NewDoTransferNFTApiCall())
-	registry.Register(ft.NewDoMintFTApiCall())
-	registry.Register(ft.NewDoTransferFTApiCall())
+	registry.RegisterFactory(func() host.HostFunction { return generic.NewDoApiCall() })
+	registry.RegisterFactory(func() host.HostFunction { return nft.NewDoMintNFTApiCall() })
+	registry.RegisterFactory(func() host.HostFunction { return nft.NewDoTransferNFTApiCall() })
+	registry.RegisterFactory(func() host.HostFunction { return ft.NewDoMintFTApiCall() })
+	registry.RegisterFactory(func() host.HostFunction { return ft.NewDoTransferFTApiCall() })
+	registry.RegisterFactory(events.NewEmitEvent)
+	registry.RegisterFactory(state.NewStateGet)
+	registry.RegisterFactory(state.NewStateSet)
+	registry.RegisterFactory(state.NewStateDelete)
+	registry.RegisterFactory(state.NewStateHas)
+	registry.RegisterFactory(state.NewStateIterPrefix)
+	registry.RegisterFactory(state.NewStateRange)
 
 	return registry
 }
 
-// Register adds a new host function to the registry.
-func (r *HostFunctionRegistry) Register(hf host.HostFunction) {
-	r.hostFunctions = append(r.hostFunctions, hf)
+// Register adds a new host function to the registry. Names are unique
+// per namespace, see host.Namespaced. Each module gets a copy of hf: host
+// functions implementing host.Cloner are cloned, and other pointers to
+// structs are shallow copied.
+func (r *HostFunctionRegistry) Register(hf host.HostFunction) error {
+	return r.add(registryEntry{
+		prototype: hf,
+		factory:   func() host.HostFunction { return cloneHostFunction(hf) },
+	})
+}
+
+// RegisterFactory adds a host function to the registry, calling factory
+// to create the instance of every module
+func (r *HostFunctionRegistry) RegisterFactory(factory host.HostFunctionFactory) error {
+	return r.add(registryEntry{
+		prototype: factory(),
+		factory:   factory,
+	})
+}
+
+func (r *HostFunctionRegistry) add(entry registryEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.indexOf(host.NamespaceOf(entry.prototype), entry.prototype.Name()) >= 0 {
+		return fmt.Errorf("%w: %s", ErrDuplicateHostFunction, qualifiedName(entry.prototype))
+	}
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+// Unregister removes the host function with the given name from the
+// default namespace and reports whether it was registered. Modules
+// instantiated earlier keep it.
+func (r *HostFunctionRegistry) Unregister(name string) bool {
+	return r.UnregisterNamespaced(host.DefaultNamespace, name)
+}
+
+// UnregisterNamespaced is like Unregister for the given namespace
+func (r *HostFunctionRegistry) UnregisterNamespaced(namespace, name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.indexOf(namespace, name)
+	if i < 0 {
+		return false
+	}
+	r.entries = append(r.entries[:i], r.entries[i+1:]...)
+	return true
+}
+
+// Lookup returns the host function registered under name in the default
+// namespace
+func (r *HostFunctionRegistry) Lookup(name string) (host.HostFunction, bool) {
+	return r.LookupNamespaced(host.DefaultNamespace, name)
+}
+
+// LookupNamespaced returns the host function registered under name in
+// the given namespace
+func (r *HostFunctionRegistry) LookupNamespaced(namespace, name string) (host.HostFunction, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i := r.indexOf(namespace, name)
+	if i < 0 {
+		return nil, false
+	}
+	return r.entries[i].prototype, true
 }
 
-// GetHostFunctions returns all registered host function names.
+// GetHostFunctions returns all registered host functions. They describe
+// the registry and are never bound to a module.
 func (r *HostFunctionRegistry) GetHostFunctions() []host.HostFunction {
-	return r.hostFunctions
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hostFunctions := make([]host.HostFunction, len(r.entries))
+	for i, entry := range r.entries {
+		hostFunctions[i] = entry.prototype
+	}
+	return hostFunctions
+}
+
+// Use adds middlewares which wrap every host function call of modules
+// instantiated afterwards. Middlewares run in the order they were added.
+func (r *HostFunctionRegistry) Use(middlewares ...host.Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// getMiddlewares returns the middlewares added through Use
+func (r *HostFunctionRegistry) getMiddlewares() []host.Middleware {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]host.Middleware{}, r.middlewares...)
+}
+
+// newInstances creates the host function instances of a single module
+func (r *HostFunctionRegistry) newInstances() []host.HostFunction {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hostFunctions := make([]host.HostFunction, len(r.entries))
+	for i, entry := range r.entries {
+		hostFunctions[i] = entry.factory()
+	}
+	return hostFunctions
+}
+
+func (r *HostFunctionRegistry) indexOf(namespace, name string) int {
+	for i, entry := range r.entries {
+		if host.NamespaceOf(entry.prototype) == namespace && entry.prototype.Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// qualifiedName returns "<namespace>.<name>" for host functions outside
+// the default namespace, and their plain name otherwise
+func qualifiedName(hf host.HostFunction) string {
+	if namespace := host.NamespaceOf(hf); namespace != host.DefaultNamespace {
+		return namespace + "." + hf.Name()
+	}
+	return hf.Name()
+}
+
+// cloneHostFunction copies hf so that Initialize does not affect the
+// original. Host functions which are not pointers to structs are shared.
+func cloneHostFunction(hf host.HostFunction) host.HostFunction {
+	if cloner, ok := hf.(host.Cloner); ok {
+		return cloner.Clone()
+	}
+
+	v := reflect.ValueOf(hf)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return hf
+	}
+	clone := reflect.New(v.Elem().Type())
+	clone.Elem().Set(v.Elem())
+
+	if cloned, ok := clone.Interface().(host.HostFunction); ok {
+		return cloned
+	}
+	return hf
 }
@@ -0,0 +1,134 @@
+package wasmbridge
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bytecodealliance/wasmtime-go"
+	wasmContext "github.com/rubixchain/rubix-wasm/go-wasm-bridge/context"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host"
+)
+
+// counterFunc is a host function which counts its calls
+type counterFunc struct {
+	calls       int
+	initialized bool
+}
+
+func (c *counterFunc) Name() string {
+	return "counter"
+}
+
+func (c *counterFunc) FuncType() *wasmtime.FuncType {
+	return wasmtime.NewFuncType(nil, []*wasmtime.ValType{wasmtime.NewValType(wasmtime.KindI32)})
+}
+
+func (c *counterFunc) Callback() host.HostFunctionCallBack {
+	return func(caller *wasmtime.Caller, args []wasmtime.Val) ([]wasmtime.Val, *wasmtime.Trap) {
+		c.calls++
+		return []wasmtime.Val{wasmtime.ValI32(0)}, nil
+	}
+}
+
+func (c *counterFunc) Initialize(allocFunc, deallocFunc *wasmtime.Func, memory *wasmtime.Memory, nodeAddress string, quorumType int, wasmCtx *wasmContext.WasmContext) {
+	c.initialized = true
+}
+
+// counterContract calls the counter host function from count_
+var counterContract = testContract(
+	`(import "env" "counter" (func $counter (result i32)))`,
+	`(func (export "count_") (param i32 i32 i32 i32) (result i32)
+    call $counter
+    drop
+    local.get 2
+    i32.const 16
+    i32.store
+    local.get 3
+    i32.const 4
+    i32.store
+    i32.const 0)`,
+)
+
+func TestRegistryFactoryInstancesPerModule(t *testing.T) {
+	var instances []*counterFunc
+	registry := NewHostFunctionRegistry()
+	err := registry.RegisterFactory(func() host.HostFunction {
+		instance := &counterFunc{}
+		instances = append(instances, instance)
+		return instance
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := NewWasmModuleFromBytes(counterContract, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewWasmModuleFromBytes(counterContract, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := first.Call("count", []byte(`{}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := second.Call("count", []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The prototype plus one instance per module
+	if len(instances) != 3 {
+		t.Fatalf("expected 3 instances, got %d", len(instances))
+	}
+	if instances[0].initialized || instances[0].calls != 0 {
+		t.Error("the prototype must not be bound to a module")
+	}
+	if instances[1].calls != 2 || instances[2].calls != 1 {
+		t.Errorf("expected 2 and 1 calls, got %d and %d", instances[1].calls, instances[2].calls)
+	}
+}
+
+func TestRegistryCopiesRegisteredStructs(t *testing.T) {
+	registered := &counterFunc{}
+	registry := NewHostFunctionRegistry()
+	if err := registry.Register(registered); err != nil {
+		t.Fatal(err)
+	}
+
+	module, err := NewWasmModuleFromBytes(counterContract, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := module.Call("count", []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if registered.initialized || registered.calls != 0 {
+		t.Error("the registered host function must not be bound to a module")
+	}
+}
+
+func TestRegistryNames(t *testing.T) {
+	registry := NewHostFunctionRegistry()
+	if err := registry.Register(&counterFunc{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register(&counterFunc{}); !errors.Is(err, ErrDuplicateHostFunction) {
+		t.Errorf("expected ErrDuplicateHostFunction, got %v", err)
+	}
+
+	if _, ok := registry.Lookup("counter"); !ok {
+		t.Error("expected counter to be registered")
+	}
+	if !registry.Unregister("counter") {
+		t.Error("expected counter to be unregistered")
+	}
+	if _, ok := registry.Lookup("counter"); ok {
+		t.Error("expected counter to be gone")
+	}
+	if registry.Unregister("counter") {
+		t.Error("expected a second Unregister to report false")
+	}
+}
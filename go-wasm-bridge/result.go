@@ -0,0 +1,63 @@
+package wasmbridge
+
+import (
+	"time"
+
+	"github.com/bytecodealliance/wasmtime-go"
+	wasmContext "github.com/rubixchain/rubix-wasm/go-wasm-bridge/context"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/state"
+)
+
+// CallResult describes a single contract call
+type CallResult struct {
+	// Output is the string returned by the contract function, or its
+	// error message if ReturnCode is non-zero
+	Output     string
+	ReturnCode int32
+
+	// GasUsed may under-report calls interrupted through their context,
+	// as Wasmtime does not flush the fuel counter on epoch interruption
+	GasUsed  uint64
+	Duration time.Duration
+
+	// HostCalls lists the host functions invoked by the contract, in order
+	HostCalls []string
+
+	// Logs and Events are recorded by host functions through WasmContext.
+	// Contracts emit events with the emit_event host function.
+	Logs   []string
+	Events []wasmContext.Event
+
+	// StateChanges lists the state writes committed by the call. Writes of
+	// calls which fail or trap are discarded.
+	StateChanges []state.Change
+
+	// StateRoot is the root hash of the contract state after the call, see
+	// state.ComputeRoot. It is computed when the call commits writes, and
+	// reused by later calls while the state store shows the state is
+	// unchanged. Otherwise it is empty, as it is if the contract has no
+	// state store or the store does not implement state.Exporter;
+	// WasmModule.StateRoot returns the current root at any time.
+	StateRoot string
+}
+
+// recordHostCall wraps a host function callback so that its invocations
+// and failures are reported in the CallResult. A failure is attributed to
+// the error the host function recorded through WasmContext.SetHostError,
+// or to the trap it returned.
+func (w *WasmModule) recordHostCall(name string, callback host.HostFunctionCallBack) host.HostFunctionCallBack {
+	return func(caller *wasmtime.Caller, args []wasmtime.Val) ([]wasmtime.Val, *wasmtime.Trap) {
+		w.hostCalls = append(w.hostCalls, name)
+
+		results, trap := callback(caller, args)
+		cause := w.wasmCtx.TakeHostError()
+		if trap != nil && w.hostErr == nil {
+			if cause == nil {
+				cause = trap
+			}
+			w.hostErr = &HostFunctionError{Function: name, Message: trap.Message(), Err: cause}
+		}
+		return results, trap
+	}
+}
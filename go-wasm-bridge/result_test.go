@@ -0,0 +1,113 @@
+package wasmbridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bytecodealliance/wasmtime-go"
+	wasmContext "github.com/rubixchain/rubix-wasm/go-wasm-bridge/context"
+)
+
+func TestCallResult(t *testing.T) {
+	module := newTestModule(t, testContract("", okFunc))
+
+	result, err := module.Execute(context.Background(), `{"ok": {}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "ok" || result.ReturnCode != 0 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if result.GasUsed == 0 {
+		t.Error("expected gas to be used")
+	}
+	if result.Duration <= 0 {
+		t.Error("expected the duration of the call to be measured")
+	}
+}
+
+func TestCallResultOfFailedCall(t *testing.T) {
+	module := newTestModule(t, testContract("", failFunc))
+
+	result, err := module.Execute(context.Background(), `{"fail": {}}`)
+	var contractErr *ContractError
+	if !errors.As(err, &contractErr) || contractErr.Code != 1 || contractErr.Message != "ok" {
+		t.Fatalf("expected a ContractError with code 1, got %#v", err)
+	}
+	if result == nil || result.ReturnCode != 1 {
+		t.Fatalf("expected a CallResult with return code 1, got %+v", result)
+	}
+}
+
+func TestTrapErrorKinds(t *testing.T) {
+	module := newTestModule(t, testContract("", trapFunc, spinFunc))
+
+	_, err := module.Execute(context.Background(), `{"trap": {}}`)
+	var trapErr *TrapError
+	if !errors.As(err, &trapErr) || trapErr.Kind != TrapUnreachable {
+		t.Fatalf("expected an unreachable TrapError, got %#v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = module.Execute(ctx, `{"spin": {}}`)
+	if !errors.As(err, &trapErr) || trapErr.Kind != TrapInterrupted {
+		t.Fatalf("expected an interrupted TrapError, got %#v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the TrapError to wrap the context error, got %v", err)
+	}
+}
+
+func TestFunctionLookupErrors(t *testing.T) {
+	module := newTestModule(t, testContract("", `(global (export "global_") i32 (i32.const 0))`))
+
+	if _, err := module.Execute(context.Background(), `{"missing": {}}`); !errors.Is(err, ErrFunctionNotFound) {
+		t.Errorf("expected ErrFunctionNotFound, got %v", err)
+	}
+	if _, err := module.Execute(context.Background(), `{"global": {}}`); !errors.Is(err, ErrNotAFunction) {
+		t.Errorf("expected ErrNotAFunction, got %v", err)
+	}
+}
+
+func TestEmitEvent(t *testing.T) {
+	module := newTestModule(t, testContract(hostImport("env", "emit_event"), hostCallFunc("emit_event")))
+
+	result, err := module.Execute(context.Background(), `{"emit_event": {"name": "bid", "data": "42"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []wasmContext.Event{{Name: "bid", Data: "42"}}
+	if len(result.Events) != 1 || result.Events[0] != expected[0] {
+		t.Errorf("expected events %+v, got %+v", expected, result.Events)
+	}
+	if len(result.HostCalls) != 1 || result.HostCalls[0] != "emit_event" {
+		t.Errorf("unexpected host calls %v", result.HostCalls)
+	}
+
+	// Events are reported for the call which emitted them only
+	result, err = module.Execute(context.Background(), `{"emit_event": {"name": "ask", "data": ""}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Events) != 1 || result.Events[0].Name != "ask" {
+		t.Errorf("unexpected events %+v", result.Events)
+	}
+}
+
+func TestHostFunctionErrorCause(t *testing.T) {
+	module := newTestModule(t, testContract(hostImport("env", "emit_event"), hostCallFunc("emit_event")))
+
+	_, err := module.Execute(context.Background(), `{"emit_event": {"data": "42"}}`)
+	var hostErr *HostFunctionError
+	if !errors.As(err, &hostErr) || hostErr.Function != "emit_event" {
+		t.Fatalf("expected a HostFunctionError of emit_event, got %#v", err)
+	}
+	var trap *wasmtime.Trap
+	if hostErr.Err == nil || errors.As(hostErr.Err, &trap) {
+		t.Errorf("expected the error of the host function as cause, got %#v", hostErr.Err)
+	}
+}
@@ -0,0 +1,162 @@
+package wasmbridge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/state"
+)
+
+// exportCountingStore counts the full state exports of a MemoryStore
+type exportCountingStore struct {
+	*state.MemoryStore
+	exports int
+}
+
+func (s *exportCountingStore) Export(contractID string) (map[string][]byte, error) {
+	s.exports++
+	return s.MemoryStore.Export(contractID)
+}
+
+// expectRoot checks root against the state of the contract in store
+func expectRoot(t *testing.T, store *state.MemoryStore, contractID, root string) {
+	t.Helper()
+
+	entries, err := store.Export(contractID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := state.ComputeRoot(entries); root != expected {
+		t.Errorf("expected state root %v, got %v", expected, root)
+	}
+}
+
+// plainStore is a StateStore which is an Exporter but no Revisioner
+type plainStore struct {
+	state.StateStore
+	exporter state.Exporter
+}
+
+func (s *plainStore) Export(contractID string) (map[string][]byte, error) {
+	return s.exporter.Export(contractID)
+}
+
+func TestStateRootOnlyComputedForWrites(t *testing.T) {
+	memory := state.NewMemoryStore()
+	store := &exportCountingStore{MemoryStore: memory}
+	var responses []string
+	module := newStateModule(t, &plainStore{StateStore: memory, exporter: store}, &responses)
+
+	// A call without writes computes no root
+	result, err := module.Call("state_has", []byte(`{"key": "count"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.StateRoot != "" || store.exports != 0 {
+		t.Errorf("expected no root for a read, got %q after %d exports", result.StateRoot, store.exports)
+	}
+
+	result, err = module.Call("state_set", []byte(`{"key": "count", "value": "1"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectRoot(t, memory, "counter", result.StateRoot)
+
+	// Without revisions the root cannot be known to be current
+	result, err = module.Call("state_has", []byte(`{"key": "count"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.StateRoot != "" || store.exports != 1 {
+		t.Errorf("expected no root for a read, got %q after %d exports", result.StateRoot, store.exports)
+	}
+
+	// The current root can always be asked for
+	root, err := module.StateRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectRoot(t, memory, "counter", root)
+}
+
+func TestStateRootReusedWhileUnchanged(t *testing.T) {
+	store := &exportCountingStore{MemoryStore: state.NewMemoryStore()}
+	var responses []string
+	module := newStateModule(t, store, &responses)
+
+	result, err := module.Call("state_set", []byte(`{"key": "count", "value": "1"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := result.StateRoot
+	expectRoot(t, store.MemoryStore, "counter", root)
+	exports := store.exports
+
+	// Reads leave the state, and so the root, unchanged
+	for i := 0; i < 3; i++ {
+		result, err := module.Call("state_has", []byte(`{"key": "count"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.StateRoot != root {
+			t.Errorf("expected the root to stay %v, got %v", root, result.StateRoot)
+		}
+	}
+	if store.exports != exports {
+		t.Errorf("expected the root to be reused, the state was exported %d more times", store.exports-exports)
+	}
+
+	// After a write made outside of the module the root is no longer
+	// reported
+	if err := store.Set("counter", "owner", []byte("alice")); err != nil {
+		t.Fatal(err)
+	}
+	result, err = module.Call("state_has", []byte(`{"key": "count"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.StateRoot != "" {
+		t.Errorf("expected no root after an outside write, got %v", result.StateRoot)
+	}
+}
+
+func TestStateRootSeesWritesOfOtherPoolInstances(t *testing.T) {
+	store := state.NewMemoryStore()
+	pool, err := NewWasmPool(writeTestContract(t, stateContract), nil, 2, WithStateStore(store), WithContractID("counter"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	first, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Release(first)
+	second, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Release(second)
+
+	if _, err := second.Call("state_set", []byte(`{"key": "owner", "value": "alice"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.Call("state_set", []byte(`{"key": "count", "value": "1"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The root second computed is stale, so it is not reported again
+	result, err := second.Call("state_has", []byte(`{"key": "count"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.StateRoot != "" {
+		t.Errorf("expected no stale root, got %v", result.StateRoot)
+	}
+	root, err := second.StateRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectRoot(t, store, "counter", root)
+}
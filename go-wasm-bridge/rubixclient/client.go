@@ -0,0 +1,204 @@
+// Package rubixclient is a typed client for the HTTP API of a Rubix node.
+// It decodes the BasicResponse envelope of every endpoint, so callers get
+// either a typed result or an error.
+package rubixclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds a single request to the node. Token operations
+// wait for quorum consensus, so it is generous; use the context of a
+// request for tighter deadlines.
+const DefaultTimeout = 5 * time.Minute
+
+// maxResponseSize caps the size of a response body read from the node
+const maxResponseSize = 32 << 20
+
+// ErrRequestFailed is matched by every APIError
+var ErrRequestFailed = errors.New("rubix node request failed")
+
+// APIError is returned when the node answers with a non-2xx status or a
+// BasicResponse with status false
+type APIError struct {
+	Endpoint   string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%v returned %d: %v", e.Endpoint, e.StatusCode, e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	return target == ErrRequestFailed
+}
+
+// Response is the BasicResponse envelope of the node, with a typed result
+type Response[T any] struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+	Result  T      `json:"result"`
+}
+
+// BasicResponse is the envelope of responses whose result is not typed
+type BasicResponse = Response[any]
+
+func (r *Response[T]) status() (bool, string) {
+	return r.Status, r.Message
+}
+
+// envelope is implemented by every Response
+type envelope interface {
+	status() (bool, string)
+}
+
+// PendingRequest is the result of token operations which wait for the
+// transaction to be signed, see Client.SignatureResponse
+type PendingRequest struct {
+	ID string `json:"id"`
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithTimeout bounds every request of the client
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithHTTPClient sends the requests of the client through httpClient
+// instead of the shared connection pool
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// Client calls the API of a single Rubix node. It is safe for concurrent
+// use. Clients share one pool of keep-alive connections unless configured
+// with WithHTTPClient.
+type Client struct {
+	nodeAddress string
+	httpClient  *http.Client
+}
+
+// sharedTransport pools the connections of all clients
+var sharedTransport = func() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = 16
+	return transport
+}()
+
+// New returns a client for the node at nodeAddress, e.g.
+// "http://localhost:20006"
+func New(nodeAddress string, opts ...Option) *Client {
+	c := &Client{
+		nodeAddress: nodeAddress,
+		httpClient: &http.Client{
+			Transport: sharedTransport,
+			Timeout:   DefaultTimeout,
+		},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+var nodeClients sync.Map
+
+// ForNode returns the default client for the node at nodeAddress, which is
+// created on first use and shared afterwards
+func ForNode(nodeAddress string) *Client {
+	if c, ok := nodeClients.Load(nodeAddress); ok {
+		return c.(*Client)
+	}
+	c, _ := nodeClients.LoadOrStore(nodeAddress, New(nodeAddress))
+	return c.(*Client)
+}
+
+// NodeAddress returns the address of the node the client calls
+func (c *Client) NodeAddress() string {
+	return c.nodeAddress
+}
+
+// Get fetches rawURL, which need not be on the node, through the
+// connection pool of the client and returns the response body
+func (c *Client) Get(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+}
+
+// postJSON posts body as JSON to endpoint and decodes the response into out
+func (c *Client) postJSON(ctx context.Context, endpoint string, query url.Values, body any, out envelope) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request of %v: %w", endpoint, err)
+	}
+	return c.post(ctx, endpoint, query, "application/json; charset=UTF-8", bytes.NewReader(payload), out)
+}
+
+// post sends body to endpoint and decodes the response into out
+func (c *Client) post(ctx context.Context, endpoint string, query url.Values, contentType string, body io.Reader, out envelope) error {
+	requestURL, err := url.JoinPath(c.nodeAddress, endpoint)
+	if err != nil {
+		return err
+	}
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %v: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("failed to read response of %v: %w", endpoint, err)
+	}
+
+	decodeErr := json.Unmarshal(data, out)
+	ok, message := out.status()
+	switch {
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		if decodeErr != nil || message == "" {
+			message = string(data)
+		}
+		return &APIError{Endpoint: endpoint, StatusCode: resp.StatusCode, Message: message}
+	case decodeErr != nil:
+		return fmt.Errorf("failed to decode response of %v: %w", endpoint, decodeErr)
+	case !ok:
+		return &APIError{Endpoint: endpoint, StatusCode: resp.StatusCode, Message: message}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package rubixclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/signer"
+)
+
+// newTestNode starts a node which answers every request with handler, and
+// returns a client for it
+func newTestNode(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	node := httptest.NewServer(handler)
+	t.Cleanup(node.Close)
+	return New(node.URL)
+}
+
+// respond writes status and body as the response of a node
+func respond(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	io.WriteString(w, body)
+}
+
+func TestCreateFT(t *testing.T) {
+	client := newTestNode(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/create-ft" || r.URL.Query().Get("ftNumStartIndex") != "3" {
+			t.Errorf("unexpected request %v", r.URL)
+		}
+		var req CreateFTRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.FTName != "gold" || req.DID != "did" {
+			t.Errorf("unexpected request body %+v, %v", req, err)
+		}
+		respond(w, http.StatusOK, `{"status": true, "message": "waiting for signature", "result": {"id": "req-1"}}`)
+	})
+
+	resp, err := client.CreateFT(context.Background(), CreateFTRequest{DID: "did", FTName: "gold", FTNumStartIndex: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Result.ID != "req-1" || resp.Message != "waiting for signature" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		message string
+	}{
+		{"status false", http.StatusOK, `{"status": false, "message": "insufficient balance"}`, "insufficient balance"},
+		{"server error", http.StatusInternalServerError, `{"status": false, "message": "quorum unavailable"}`, "quorum unavailable"},
+		{"plain text error", http.StatusBadGateway, "bad gateway", "bad gateway"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestNode(t, func(w http.ResponseWriter, r *http.Request) {
+				respond(w, tt.status, tt.body)
+			})
+
+			_, err := client.InitiateFTTransfer(context.Background(), TransferFTRequest{FTName: "gold"})
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || !errors.Is(err, ErrRequestFailed) {
+				t.Fatalf("expected an APIError, got %v", err)
+			}
+			if apiErr.Endpoint != "/api/initiate-ft-transfer" || apiErr.StatusCode != tt.status || apiErr.Message != tt.message {
+				t.Errorf("unexpected error %+v", apiErr)
+			}
+		})
+	}
+}
+
+func TestUndecodableResponse(t *testing.T) {
+	client := newTestNode(t, func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusOK, `{"status": true, "result": {"id": 42}}`)
+	})
+
+	_, err := client.DeployNFT(context.Background(), DeployNFTRequest{NFT: "nft"})
+	if err == nil || errors.Is(err, ErrRequestFailed) {
+		t.Fatalf("expected a decode error, got %v", err)
+	}
+}
+
+func TestCreateNFTUploadsFiles(t *testing.T) {
+	dir := t.TempDir()
+	artifact, metadata := filepath.Join(dir, "art.png"), filepath.Join(dir, "meta.json")
+	if err := os.WriteFile(artifact, []byte("image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(metadata, []byte(`{"name": "art"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := newTestNode(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Error(err)
+			return
+		}
+		if r.FormValue("did") != "did" {
+			t.Errorf("unexpected did %q", r.FormValue("did"))
+		}
+		for field, expected := range map[string]string{"artifact": "image", "metadata": `{"name": "art"}`} {
+			file, _, err := r.FormFile(field)
+			if err != nil {
+				t.Error(err)
+				continue
+			}
+			content, _ := io.ReadAll(file)
+			if string(content) != expected {
+				t.Errorf("unexpected %v %q", field, content)
+			}
+		}
+		respond(w, http.StatusOK, `{"status": true, "result": "nft-1"}`)
+	})
+
+	resp, err := client.CreateNFT(context.Background(), CreateNFTRequest{DID: "did", Artifact: artifact, Metadata: metadata})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Result != "nft-1" {
+		t.Errorf("expected NFT ID nft-1, got %q", resp.Result)
+	}
+}
+
+func TestGetSmartContractData(t *testing.T) {
+	client := newTestNode(t, func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusOK, `{"status": true, "SCTDataReply": [{"BlockNo": 2, "BlockId": "2-abc", "SmartContractData": "{}"}]}`)
+	})
+
+	resp, err := client.GetSmartContractData(context.Background(), SmartContractDataRequest{Token: "contract", Latest: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.SCTDataReply) != 1 || resp.SCTDataReply[0].BlockId != "2-abc" {
+		t.Errorf("unexpected token chain %+v", resp.SCTDataReply)
+	}
+}
+
+func TestSignatureResponseRevealsPassword(t *testing.T) {
+	client := newTestNode(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"password":"hunter2"`) {
+			t.Errorf("expected the password in the request body, got %s", body)
+		}
+		respond(w, http.StatusOK, `{"status": true, "message": "signed"}`)
+	})
+
+	req := SignatureRequest{ID: "req-1", Mode: 4, Password: signer.Secret("hunter2")}
+	if _, err := client.SignatureResponse(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCancelledRequest(t *testing.T) {
+	client := newTestNode(t, func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusOK, `{"status": true}`)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.InitiateFTTransfer(ctx, TransferFTRequest{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
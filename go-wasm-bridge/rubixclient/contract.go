@@ -0,0 +1,33 @@
+package rubixclient
+
+import "context"
+
+// SmartContractDataRequest is the request of
+// /api/get-smart-contract-token-chain-data
+type SmartContractDataRequest struct {
+	Token  string `json:"token"`
+	Latest bool   `json:"latest"`
+}
+
+// SmartContractDataReply is the response of
+// /api/get-smart-contract-token-chain-data
+type SmartContractDataReply struct {
+	BasicResponse
+	SCTDataReply []SCTDataReply
+}
+
+// SCTDataReply is a block of the token chain of a smart contract
+type SCTDataReply struct {
+	BlockNo           uint64
+	BlockId           string
+	SmartContractData string
+}
+
+// GetSmartContractData returns the token chain of a smart contract
+func (c *Client) GetSmartContractData(ctx context.Context, req SmartContractDataRequest) (*SmartContractDataReply, error) {
+	resp := &SmartContractDataReply{}
+	if err := c.postJSON(ctx, "/api/get-smart-contract-token-chain-data", nil, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
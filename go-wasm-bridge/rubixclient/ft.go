@@ -0,0 +1,50 @@
+package rubixclient
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// CreateFTRequest is the request of /api/create-ft
+type CreateFTRequest struct {
+	DID             string `json:"did"`
+	FTCount         int32  `json:"ft_count"`
+	FTName          string `json:"ft_name"`
+	FTNumStartIndex int32  `json:"ft_num_start_index"`
+	TokenCount      int32  `json:"token_count"`
+}
+
+// TransferFTRequest is the request of /api/initiate-ft-transfer
+type TransferFTRequest struct {
+	FTCount    int32  `json:"ft_count"`
+	FTName     string `json:"ft_name"`
+	CreatorDID string `json:"creatorDID"`
+	QuorumType int32  `json:"quorum_type"`
+	Comment    string `json:"comment"`
+	Receiver   string `json:"receiver"`
+	Sender     string `json:"sender"`
+}
+
+// CreateFT creates fungible tokens. The transaction waits for its
+// signature, see SignatureResponse.
+func (c *Client) CreateFT(ctx context.Context, req CreateFTRequest) (*Response[PendingRequest], error) {
+	query := url.Values{}
+	query.Set("ftNumStartIndex", strconv.Itoa(int(req.FTNumStartIndex)))
+
+	resp := &Response[PendingRequest]{}
+	if err := c.postJSON(ctx, "/api/create-ft", query, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// InitiateFTTransfer transfers fungible tokens. The transaction waits for
+// its signature, see SignatureResponse.
+func (c *Client) InitiateFTTransfer(ctx context.Context, req TransferFTRequest) (*Response[PendingRequest], error) {
+	resp := &Response[PendingRequest]{}
+	if err := c.postJSON(ctx, "/api/initiate-ft-transfer", nil, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
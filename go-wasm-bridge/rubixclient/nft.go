@@ -0,0 +1,97 @@
+package rubixclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+)
+
+// CreateNFTRequest is the request of /api/create-nft. Artifact and
+// Metadata are paths of files which are uploaded to the node.
+type CreateNFTRequest struct {
+	DID      string
+	Artifact string
+	Metadata string
+}
+
+// DeployNFTRequest is the request of /api/deploy-nft
+type DeployNFTRequest struct {
+	NFT        string `json:"nft"`
+	DID        string `json:"did"`
+	QuorumType int32  `json:"quorum_type"`
+}
+
+// ExecuteNFTRequest is the request of /api/execute-nft
+type ExecuteNFTRequest struct {
+	NFT        string  `json:"nft"`
+	Owner      string  `json:"owner"`
+	Receiver   string  `json:"receiver"`
+	Comment    string  `json:"comment"`
+	NFTValue   float64 `json:"nft_value"`
+	NFTData    string  `json:"nft_data"`
+	QuorumType int32   `json:"quorum_type"`
+}
+
+// CreateNFT uploads an NFT to the node. The result is the ID of the NFT.
+func (c *Client) CreateNFT(ctx context.Context, req CreateNFTRequest) (*Response[string], error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("did", req.DID); err != nil {
+		return nil, err
+	}
+	if err := addFormFile(writer, "artifact", req.Artifact); err != nil {
+		return nil, err
+	}
+	if err := addFormFile(writer, "metadata", req.Metadata); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	resp := &Response[string]{}
+	if err := c.post(ctx, "/api/create-nft", nil, writer.FormDataContentType(), &body, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DeployNFT deploys a created NFT. The transaction waits for its
+// signature, see SignatureResponse.
+func (c *Client) DeployNFT(ctx context.Context, req DeployNFTRequest) (*Response[PendingRequest], error) {
+	resp := &Response[PendingRequest]{}
+	if err := c.postJSON(ctx, "/api/deploy-nft", nil, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ExecuteNFT transfers an NFT. The transaction waits for its signature,
+// see SignatureResponse.
+func (c *Client) ExecuteNFT(ctx context.Context, req ExecuteNFTRequest) (*Response[PendingRequest], error) {
+	resp := &Response[PendingRequest]{}
+	if err := c.postJSON(ctx, "/api/execute-nft", nil, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func addFormFile(writer *multipart.Writer, field, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %v file: %w", field, err)
+	}
+	defer file.Close()
+
+	part, err := writer.CreateFormFile(field, path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return fmt.Errorf("failed to read %v file: %w", field, err)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package rubixclient
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/signer"
+)
+
+// SignatureRequest is the request of /api/signature-response, which
+// answers the signature request of a pending transaction. The password is
+// only revealed in the request body.
+type SignatureRequest struct {
+	ID       string
+	Mode     int
+	Password signer.Secret
+}
+
+func (r SignatureRequest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ID       string `json:"id"`
+		Mode     int    `json:"mode"`
+		Password string `json:"password"`
+	}{r.ID, r.Mode, r.Password.Reveal()})
+}
+
+// SignatureResponse answers the signature request of a pending
+// transaction, and returns once the node has processed it
+func (c *Client) SignatureResponse(ctx context.Context, req SignatureRequest) (*Response[json.RawMessage], error) {
+	resp := &Response[json.RawMessage]{}
+	if err := c.postJSON(ctx, "/api/signature-response", nil, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,129 @@
+// Package signer decides how the pending transactions a contract starts on
+// the Rubix node are signed. Token operations such as FT transfers wait on
+// the node until their signature request is answered; the Signer of a
+// module gives that answer.
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrDeclined is returned by signers which refuse to sign a transaction
+var ErrDeclined = errors.New("transaction declined by signer")
+
+// ErrNoSigner is returned when a contract starts a transaction which needs
+// a signature, but its module has no Signer
+var ErrNoSigner = errors.New("no signer configured")
+
+// redacted replaces secrets wherever they are printed or encoded
+const redacted = "[REDACTED]"
+
+// Secret is a credential which is redacted when printed, formatted or
+// encoded as JSON. Reveal returns its value.
+type Secret string
+
+// Reveal returns the value of the secret
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return redacted
+}
+
+// Format redacts the secret for every formatting verb, including those
+// which would bypass String
+func (s Secret) Format(f fmt.State, verb rune) {
+	io.WriteString(f, redacted)
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redacted + `"`), nil
+}
+
+// Transaction summarises a pending transaction waiting for its signature
+type Transaction struct {
+	// RequestID is the ID of the signature request on the node
+	RequestID string
+
+	// Operation is the capability of the host function which started the
+	// transaction, e.g. "ft.transfer"
+	Operation string
+
+	// ContractID is the contract which started the transaction
+	ContractID string
+
+	// NodeAddress is the node the transaction waits on
+	NodeAddress string
+
+	// Details is the request which started the transaction, e.g. a
+	// rubixclient.TransferFTRequest
+	Details any
+}
+
+// Signature is the answer to a signature request
+type Signature struct {
+	Mode     int
+	Password Secret
+}
+
+// Signer answers the signature requests of pending transactions. Returning
+// an error, e.g. ErrDeclined, fails the host function which started the
+// transaction.
+type Signer interface {
+	Sign(ctx context.Context, tx Transaction) (Signature, error)
+}
+
+// SignerFunc is a Signer implemented by a callback, e.g. one which asks an
+// operator to approve every transaction
+type SignerFunc func(ctx context.Context, tx Transaction) (Signature, error)
+
+func (f SignerFunc) Sign(ctx context.Context, tx Transaction) (Signature, error) {
+	return f(ctx, tx)
+}
+
+// NewStaticSigner signs every transaction with password
+func NewStaticSigner(password string) Signer {
+	return SignerFunc(func(ctx context.Context, tx Transaction) (Signature, error) {
+		return Signature{Password: Secret(password)}, nil
+	})
+}
+
+// NewEnvSigner signs every transaction with the password in the
+// environment variable name. It is read on every signature, so it can be
+// rotated without reloading the contract.
+func NewEnvSigner(name string) Signer {
+	return SignerFunc(func(ctx context.Context, tx Transaction) (Signature, error) {
+		password, ok := os.LookupEnv(name)
+		if !ok || password == "" {
+			return Signature{}, fmt.Errorf("signer password variable %v is not set", name)
+		}
+		return Signature{Password: Secret(password)}, nil
+	})
+}
+
+// NewFileSigner signs every transaction with the password stored in the
+// file at path, without its trailing newline. It is read on every
+// signature, so it can be rotated without reloading the contract.
+func NewFileSigner(path string) Signer {
+	return SignerFunc(func(ctx context.Context, tx Transaction) (Signature, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Signature{}, fmt.Errorf("failed to read signer password file: %w", err)
+		}
+		password := strings.TrimRight(string(data), "\r\n")
+		if password == "" {
+			return Signature{}, fmt.Errorf("signer password file %v is empty", path)
+		}
+		return Signature{Password: Secret(password)}, nil
+	})
+}
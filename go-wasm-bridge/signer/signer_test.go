@@ -0,0 +1,94 @@
+package signer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecretIsRedacted(t *testing.T) {
+	secret := Secret("hunter2")
+	signature := Signature{Mode: 0, Password: secret}
+
+	printed := []string{
+		secret.String(),
+		fmt.Sprint(secret),
+		fmt.Sprintf("%s %v %q %x %#v", secret, secret, secret, secret, secret),
+		fmt.Sprintf("%+v %#v", signature, signature),
+	}
+	encoded, err := json.Marshal(signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	printed = append(printed, string(encoded))
+
+	for _, text := range printed {
+		if strings.Contains(text, "hunter2") {
+			t.Errorf("secret leaked in %q", text)
+		}
+	}
+	if secret.Reveal() != "hunter2" {
+		t.Errorf("expected Reveal to return the secret, got %q", secret.Reveal())
+	}
+}
+
+func TestStaticSigner(t *testing.T) {
+	signature, err := NewStaticSigner("hunter2").Sign(context.Background(), Transaction{RequestID: "req-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signature.Password.Reveal() != "hunter2" {
+		t.Errorf("unexpected password %q", signature.Password.Reveal())
+	}
+}
+
+func TestEnvSigner(t *testing.T) {
+	s := NewEnvSigner("SIGNER_TEST_PASSWORD")
+
+	if _, err := s.Sign(context.Background(), Transaction{}); err == nil {
+		t.Fatal("expected an error for an unset variable")
+	}
+
+	// The variable is read on every signature
+	for _, password := range []string{"first", "second"} {
+		t.Setenv("SIGNER_TEST_PASSWORD", password)
+		signature, err := s.Sign(context.Background(), Transaction{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if signature.Password.Reveal() != password {
+			t.Errorf("expected password %q, got %q", password, signature.Password.Reveal())
+		}
+	}
+}
+
+func TestFileSigner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	s := NewFileSigner(path)
+
+	if _, err := s.Sign(context.Background(), Transaction{}); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+
+	if err := os.WriteFile(path, []byte("\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Sign(context.Background(), Transaction{}); err == nil {
+		t.Fatal("expected an error for an empty file")
+	}
+
+	if err := os.WriteFile(path, []byte("hunter2\r\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	signature, err := s.Sign(context.Background(), Transaction{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signature.Password.Reveal() != "hunter2" {
+		t.Errorf("expected the trailing newline to be trimmed, got %q", signature.Password.Reveal())
+	}
+}
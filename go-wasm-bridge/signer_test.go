@@ -0,0 +1,121 @@
+package wasmbridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/signer"
+)
+
+// transferContract calls do_transfer_ft with its input
+var transferContract = testContract(hostImport("env", "do_transfer_ft"), hostCallFunc("do_transfer_ft"))
+
+// transferInput is the input of a transfer of one FT
+const transferInput = `{"ft_count": 1, "ft_name": "gold", "sender": "alice", "receiver": "bob"}`
+
+// testNode records the requests of a Rubix node which accepts every
+// transaction
+type testNode struct {
+	mu       sync.Mutex
+	requests map[string]string
+}
+
+func newTestNode(t *testing.T) (*testNode, string) {
+	t.Helper()
+
+	node := &testNode{requests: make(map[string]string)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		node.mu.Lock()
+		node.requests[r.URL.Path] = string(body)
+		node.mu.Unlock()
+
+		if r.URL.Path == "/api/initiate-ft-transfer" {
+			io.WriteString(w, `{"status": true, "result": {"id": "req-1"}}`)
+			return
+		}
+		io.WriteString(w, `{"status": true, "message": "signed"}`)
+	}))
+	t.Cleanup(server.Close)
+	return node, server.URL
+}
+
+func (n *testNode) request(path string) (string, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	body, ok := n.requests[path]
+	return body, ok
+}
+
+func TestTokenOperationsRequireSigner(t *testing.T) {
+	node, address := newTestNode(t)
+	module := newTestModule(t, transferContract, WithRubixNodeAddress(address))
+
+	_, err := module.Call("do_transfer_ft", []byte(transferInput))
+	var hostErr *HostFunctionError
+	if !errors.As(err, &hostErr) || !strings.Contains(hostErr.Message, signer.ErrNoSigner.Error()) {
+		t.Fatalf("expected a HostFunctionError for a missing signer, got %v", err)
+	}
+	if _, ok := node.request("/api/initiate-ft-transfer"); ok {
+		t.Error("expected no transaction to be started without a signer")
+	}
+}
+
+func TestSignerSignsPendingTransactions(t *testing.T) {
+	node, address := newTestNode(t)
+
+	var signed []signer.Transaction
+	sign := signer.SignerFunc(func(ctx context.Context, tx signer.Transaction) (signer.Signature, error) {
+		signed = append(signed, tx)
+		return signer.Signature{Password: "hunter2"}, nil
+	})
+	module := newTestModule(t, transferContract, WithRubixNodeAddress(address), WithSigner(sign), WithContractID("exchange"))
+
+	if _, err := module.Call("do_transfer_ft", []byte(transferInput)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(signed) != 1 {
+		t.Fatalf("expected one transaction to be signed, got %+v", signed)
+	}
+	tx := signed[0]
+	if tx.RequestID != "req-1" || tx.Operation != "ft.transfer" || tx.ContractID != "exchange" || tx.NodeAddress != address {
+		t.Errorf("unexpected transaction %+v", tx)
+	}
+
+	body, ok := node.request("/api/signature-response")
+	if !ok {
+		t.Fatal("expected the signature to be sent to the node")
+	}
+	var signature struct {
+		ID       string `json:"id"`
+		Password string `json:"password"`
+	}
+	if err := json.Unmarshal([]byte(body), &signature); err != nil || signature.ID != "req-1" || signature.Password != "hunter2" {
+		t.Errorf("unexpected signature response %s", body)
+	}
+}
+
+func TestDeclinedTransaction(t *testing.T) {
+	node, address := newTestNode(t)
+	decline := signer.SignerFunc(func(ctx context.Context, tx signer.Transaction) (signer.Signature, error) {
+		return signer.Signature{}, signer.ErrDeclined
+	})
+	module := newTestModule(t, transferContract, WithRubixNodeAddress(address), WithSigner(decline))
+
+	var hostErr *HostFunctionError
+	if _, err := module.Call("do_transfer_ft", []byte(transferInput)); !errors.As(err, &hostErr) {
+		t.Fatalf("expected a HostFunctionError, got %v", err)
+	}
+	if _, ok := node.request("/api/signature-response"); ok {
+		t.Error("expected a declined transaction not to be signed")
+	}
+}
@@ -0,0 +1,161 @@
+package wasmbridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/state"
+)
+
+// constFunc returns the contract function <export>_, which returns output
+// as its JSON encoded output. The output is stored at offset 128.
+func constFunc(export, output string) string {
+	encoded, _ := json.Marshal(output)
+	data := ""
+	for _, b := range encoded {
+		data += fmt.Sprintf(`\%02x`, b)
+	}
+	return fmt.Sprintf(`(data (i32.const 128) "%s")
+  (func (export "%s_") (param i32 i32 i32 i32) (result i32)
+    local.get 2
+    i32.const 128
+    i32.store
+    local.get 3
+    i32.const %d
+    i32.store
+    i32.const 0)`, data, export, len(encoded))
+}
+
+// newSnapshotStore returns a store with a state for the contract v1
+func newSnapshotStore(t *testing.T) *state.MemoryStore {
+	t.Helper()
+
+	store := state.NewMemoryStore()
+	for key, value := range map[string]string{"count": "1", "owner": "alice"} {
+		if err := store.Set("v1", key, []byte(value)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store
+}
+
+func TestExportImportState(t *testing.T) {
+	store := newSnapshotStore(t)
+	from := newTestModule(t, txnContract, WithStateStore(store), WithContractID("v1"))
+	to := newTestModule(t, txnContract, WithStateStore(store), WithContractID("v2"))
+
+	var snapshot bytes.Buffer
+	if err := from.ExportState(&snapshot); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := to.ImportState(&snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 {
+		t.Errorf("expected 2 changes, got %+v", changes)
+	}
+
+	fromRoot, err := from.StateRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	toRoot, err := to.StateRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromRoot != toRoot {
+		t.Errorf("expected the imported state to have root %v, got %v", fromRoot, toRoot)
+	}
+}
+
+func TestImportStateRootMismatch(t *testing.T) {
+	store := newSnapshotStore(t)
+	module := newTestModule(t, txnContract, WithStateStore(store), WithContractID("v1"))
+
+	var exported bytes.Buffer
+	if err := module.ExportState(&exported); err != nil {
+		t.Fatal(err)
+	}
+	var snapshot state.Snapshot
+	if err := json.Unmarshal(exported.Bytes(), &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	snapshot.Entries["count"] = "1000"
+	tampered, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := module.ImportState(bytes.NewReader(tampered)); !errors.Is(err, state.ErrRootMismatch) {
+		t.Fatalf("expected ErrRootMismatch, got %v", err)
+	}
+	if value, err := store.Get("v1", "count"); err != nil || string(value) != "1" {
+		t.Errorf("expected the state to be left alone, got %q, %v", value, err)
+	}
+}
+
+func TestImportStateWithoutRoot(t *testing.T) {
+	store := state.NewMemoryStore()
+	module := newTestModule(t, txnContract, WithStateStore(store), WithContractID("v1"))
+
+	if _, err := module.ImportState(strings.NewReader(`{"entries": {"count": "7"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := store.Get("v1", "count"); err != nil || string(value) != "7" {
+		t.Errorf("expected count 7, got %q, %v", value, err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	store := newSnapshotStore(t)
+	migrated := map[string]string{"count": "1", "owners": "alice", "version": "2"}
+	output, err := json.Marshal(migrated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	module := newTestModule(t, testContract("", constFunc(MigrateFunction, string(output))),
+		WithStateStore(store), WithContractID("v2"))
+
+	result, err := module.Migrate(context.Background(), "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Changes) != 3 {
+		t.Errorf("expected 3 changes, got %+v", result.Changes)
+	}
+
+	entries, err := store.Export("v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string, len(entries))
+	for key, value := range entries {
+		got[key] = string(value)
+	}
+	if !reflect.DeepEqual(got, migrated) {
+		t.Errorf("expected migrated state %v, got %v", migrated, got)
+	}
+	if result.StateRoot != state.ComputeRoot(entries) {
+		t.Errorf("expected state root %v, got %v", state.ComputeRoot(entries), result.StateRoot)
+	}
+
+	// The old state is left in place
+	if value, err := store.Get("v1", "owner"); err != nil || string(value) != "alice" {
+		t.Errorf("expected the state of v1 to be kept, got %q, %v", value, err)
+	}
+}
+
+func TestMigrateRequiresMigrateFunction(t *testing.T) {
+	module := newTestModule(t, txnContract, WithStateStore(newSnapshotStore(t)), WithContractID("v2"))
+
+	if _, err := module.Migrate(context.Background(), "v1"); err == nil {
+		t.Fatal("expected an error for a contract without a migrate function")
+	}
+}
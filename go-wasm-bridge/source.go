@@ -0,0 +1,53 @@
+package wasmbridge
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/bytecodealliance/wasmtime-go"
+)
+
+// wasmMagic prefixes every binary WASM module
+var wasmMagic = []byte("\x00asm")
+
+// NewWasmModuleFromBytes is like NewWasmModule, but takes the contract
+// itself, either as a WASM binary or as WAT text
+func NewWasmModuleFromBytes(wasmBytes []byte, registry *HostFunctionRegistry, wasmModuleOpts ...WasmModuleOption) (*WasmModule, error) {
+	wasmModule := newWasmModule(registry, wasmModuleOpts...)
+
+	if err := wasmModule.compile(wasmBytes); err != nil {
+		return nil, err
+	}
+
+	if err := wasmModule.instantiate(); err != nil {
+		return nil, err
+	}
+
+	return wasmModule, nil
+}
+
+// NewWasmModuleFromReader is like NewWasmModuleFromBytes, but reads the
+// contract from r
+func NewWasmModuleFromReader(r io.Reader, registry *HostFunctionRegistry, wasmModuleOpts ...WasmModuleOption) (*WasmModule, error) {
+	wasmBytes, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read contract: %w", err)
+	}
+
+	return NewWasmModuleFromBytes(wasmBytes, registry, wasmModuleOpts...)
+}
+
+// toWasmBinary converts WAT text to a WASM binary. WASM binaries are
+// returned as is.
+func toWasmBinary(src []byte) ([]byte, error) {
+	if bytes.HasPrefix(src, wasmMagic) {
+		return src, nil
+	}
+
+	wasmBytes, err := wasmtime.Wat2Wasm(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("contract is neither a WASM binary nor valid WAT: %w", err)
+	}
+	return wasmBytes, nil
+}
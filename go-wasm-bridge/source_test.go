@@ -0,0 +1,61 @@
+package wasmbridge
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bytecodealliance/wasmtime-go"
+)
+
+func TestLoadContractSources(t *testing.T) {
+	wat := testContract("", okFunc)
+	wasmBytes, err := wasmtime.Wat2Wasm(string(wat))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	load := map[string]func() (*WasmModule, error){
+		"WAT bytes": func() (*WasmModule, error) {
+			return NewWasmModuleFromBytes(wat, NewHostFunctionRegistry())
+		},
+		"WASM bytes": func() (*WasmModule, error) {
+			return NewWasmModuleFromBytes(wasmBytes, NewHostFunctionRegistry())
+		},
+		"reader": func() (*WasmModule, error) {
+			return NewWasmModuleFromReader(bytes.NewReader(wasmBytes), NewHostFunctionRegistry())
+		},
+		"WASM file": func() (*WasmModule, error) {
+			return NewWasmModule(writeTestContract(t, wat), NewHostFunctionRegistry())
+		},
+	}
+	for name, load := range load {
+		t.Run(name, func(t *testing.T) {
+			module, err := load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result, err := module.Call("ok", []byte(`{}`)); err != nil || result.Output != "ok" {
+				t.Fatalf("call returned %+v, %v", result, err)
+			}
+		})
+	}
+}
+
+func TestLoadInvalidContract(t *testing.T) {
+	if _, err := NewWasmModuleFromBytes([]byte("(module"), NewHostFunctionRegistry()); err == nil {
+		t.Error("expected invalid WAT to be rejected")
+	}
+	if _, err := NewWasmModuleFromBytes([]byte("\x00asm\x01"), NewHostFunctionRegistry()); err == nil {
+		t.Error("expected a truncated WASM binary to be rejected")
+	}
+}
+
+func TestLoadPoolFromBytes(t *testing.T) {
+	pool, err := NewWasmPoolFromBytes(testContract("", okFunc), nil, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output, err := pool.CallFunction(`{"ok": {}}`); err != nil || output != "ok" {
+		t.Fatalf("pooled call returned %q, %v", output, err)
+	}
+}
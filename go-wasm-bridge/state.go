@@ -0,0 +1,207 @@
+package wasmbridge
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/state"
+)
+
+// WithStateStore backs the state_get, state_set, state_delete and
+// state_has host functions of the contract with store
+func WithStateStore(store state.StateStore) WasmModuleOption {
+	return func(w *WasmModule) {
+		w.stateStore = store
+	}
+}
+
+// WithContractID sets the ID under which the state of the contract is
+// kept. It defaults to the SHA-256 hash of the contract binary.
+func WithContractID(contractID string) WasmModuleOption {
+	return func(w *WasmModule) {
+		w.contractID = contractID
+	}
+}
+
+// ContractID returns the ID under which the state of the contract is kept
+func (w *WasmModule) ContractID() string {
+	return w.contractID
+}
+
+// cachedRoot is the state root of the contract at a revision of the state
+// store, see state.Revisioner
+type cachedRoot struct {
+	revision uint64
+	root     string
+}
+
+// StateRoot returns the root hash of the committed state of the contract,
+// see state.ComputeRoot. The root is only recomputed if the state changed
+// since it was last computed, which requires the state store to be, or
+// wrap, a state.Revisioner. Otherwise it is recomputed on every call.
+func (w *WasmModule) StateRoot() (string, error) {
+	store := w.wasmCtx.StateStore()
+	if store == nil {
+		return "", errors.New("contract has no state store")
+	}
+
+	revisioner, ok := state.Find[state.Revisioner](store)
+	if !ok {
+		return state.Root(store, w.contractID)
+	}
+
+	// Read the revision first, so that a concurrent write makes the next
+	// call recompute the root rather than reuse a stale one
+	revision := revisioner.Revision(w.contractID)
+	if w.root != nil && w.root.revision == revision {
+		return w.root.root, nil
+	}
+	root, err := state.Root(store, w.contractID)
+	if err != nil {
+		return "", err
+	}
+	w.root = &cachedRoot{revision: revision, root: root}
+	return root, nil
+}
+
+// callStateRoot returns the state root reported in the result of a call.
+// It is only computed if the call committed writes. Other calls report
+// the root computed last, provided the state store is a state.Revisioner
+// which shows that the state has not changed since.
+func (w *WasmModule) callStateRoot(committed bool) string {
+	if committed {
+		root, _ := w.StateRoot()
+		return root
+	}
+
+	revisioner, ok := state.Find[state.Revisioner](w.wasmCtx.StateStore())
+	if !ok || w.root == nil || w.root.revision != revisioner.Revision(w.contractID) {
+		return ""
+	}
+	return w.root.root
+}
+
+// ExportState writes the committed state of the contract to out as a JSON
+// state.Snapshot
+func (w *WasmModule) ExportState(out io.Writer) error {
+	if w.stateStore == nil {
+		return errors.New("contract has no state store")
+	}
+
+	snapshot, err := state.NewSnapshot(w.stateStore, w.contractID)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(snapshot)
+}
+
+// ImportState replaces the state of the contract with a JSON
+// state.Snapshot read from in, and returns the changes it made. The
+// snapshot may have been exported from another contract ID. Its root is
+// checked if present.
+func (w *WasmModule) ImportState(in io.Reader) ([]state.Change, error) {
+	if w.stateStore == nil {
+		return nil, errors.New("contract has no state store")
+	}
+
+	var snapshot state.Snapshot
+	if err := json.NewDecoder(in).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode state snapshot: %w", err)
+	}
+	return snapshot.Restore(w.stateStore, w.contractID)
+}
+
+// StateUsage returns the size of the committed state of the contract. The
+// state store must be, or wrap, a state.QuotaStore.
+func (w *WasmModule) StateUsage() (state.Usage, error) {
+	store, ok := state.Find[*state.QuotaStore](w.stateStore)
+	if !ok {
+		return state.Usage{}, errors.New("contract state store does not account usage")
+	}
+	return store.Usage(w.contractID)
+}
+
+// WithBlockID tags the state version committed by a call with the Rubix
+// block it was executed for, see state.VersionedStore
+func WithBlockID(blockID string) CallOption {
+	return func(c *callConfig) {
+		c.blockID = blockID
+	}
+}
+
+// StateVersions returns the retained versions of the contract state. A
+// call makes a version only if it commits state changes, so sequence
+// numbers count state changes rather than calls, see state.Version.
+func (w *WasmModule) StateVersions() ([]state.Version, error) {
+	store, err := w.versionedStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.Versions(w.contractID), nil
+}
+
+// StateAt returns the contract state as of version seq
+func (w *WasmModule) StateAt(seq uint64) (map[string][]byte, error) {
+	store, err := w.versionedStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.StateAt(w.contractID, seq)
+}
+
+// StateAtBlock returns the contract state as of the latest call executed
+// for blockID, see WithBlockID
+func (w *WasmModule) StateAtBlock(blockID string) (map[string][]byte, error) {
+	store, err := w.versionedStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.StateAtBlock(w.contractID, blockID)
+}
+
+// RestoreState sets the contract state back to version seq and returns the
+// version recording the restore
+func (w *WasmModule) RestoreState(seq uint64) (state.Version, error) {
+	store, err := w.versionedStore()
+	if err != nil {
+		return state.Version{}, err
+	}
+	return store.Restore(w.contractID, seq)
+}
+
+func (w *WasmModule) versionedStore() (*state.VersionedStore, error) {
+	store, ok := state.Find[*state.VersionedStore](w.stateStore)
+	if !ok {
+		return nil, errors.New("contract state store does not keep versions")
+	}
+	return store, nil
+}
+
+// beginStateTxn routes the state host functions through a transaction on
+// the state store for the duration of a call. It returns nil if the
+// contract has no state store. The returned function restores the store.
+func (w *WasmModule) beginStateTxn(cfg *callConfig) (*state.Txn, func()) {
+	base := w.wasmCtx.StateStore()
+	if base == nil {
+		return nil, func() {}
+	}
+
+	txn := state.NewTxn(base).WithBlockID(cfg.blockID)
+	w.wasmCtx.WithStateStore(txn)
+	return txn, func() {
+		txn.Rollback()
+		w.wasmCtx.WithStateStore(base)
+	}
+}
+
+// contentContractID derives the default contract ID from the binary
+func contentContractID(wasmBytes []byte) string {
+	hash := sha256.Sum256(wasmBytes)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,257 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"unicode/utf8"
+)
+
+// contractIDPattern restricts contract IDs to names which are safe to use
+// as file names
+var contractIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+// FileStore is a StateStore which keeps the state of every contract in a
+// JSON file named <contract ID>.json, so that operators can inspect it.
+// Values must be valid UTF-8. Its revisions only count the writes made
+// through the store, not edits of the state files by other processes.
+type FileStore struct {
+	dir string
+
+	mu        sync.RWMutex
+	revisions map[string]uint64
+}
+
+// NewFileStore returns a FileStore backed by stateDir, which is created if
+// it does not exist
+func NewFileStore(stateDir string) (*FileStore, error) {
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	return &FileStore{dir: stateDir, revisions: make(map[string]uint64)}, nil
+}
+
+func (s *FileStore) Get(contractID, key string) ([]byte, error) {
+	if err := validateKey(contractID, key); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := s.load(contractID)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := entries[key]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return []byte(value), nil
+}
+
+func (s *FileStore) Set(contractID, key string, value []byte) error {
+	if err := validateKey(contractID, key); err != nil {
+		return err
+	}
+	if !utf8.Valid(value) {
+		return fmt.Errorf("value of state key %v is not valid UTF-8", key)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load(contractID)
+	if err != nil {
+		return err
+	}
+	entries[key] = string(value)
+	return s.save(contractID, entries)
+}
+
+func (s *FileStore) Delete(contractID, key string) error {
+	if err := validateKey(contractID, key); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load(contractID)
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[key]; !ok {
+		return nil
+	}
+	delete(entries, key)
+	return s.save(contractID, entries)
+}
+
+func (s *FileStore) Has(contractID, key string) (bool, error) {
+	if err := validateKey(contractID, key); err != nil {
+		return false, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := s.load(contractID)
+	if err != nil {
+		return false, err
+	}
+	_, ok := entries[key]
+	return ok, nil
+}
+
+func (s *FileStore) Iterate(contractID, start, end string, fn func(key string, value []byte) bool) error {
+	s.mu.RLock()
+	entries, err := s.load(contractID)
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range sortedKeys(entries, start, end) {
+		if !fn(key, []byte(entries[key])) {
+			break
+		}
+	}
+	return nil
+}
+
+// Export returns the state of a contract
+func (s *FileStore) Export(contractID string) (map[string][]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored, err := s.load(contractID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string][]byte, len(stored))
+	for key, value := range stored {
+		entries[key] = []byte(value)
+	}
+	return entries, nil
+}
+
+// Apply applies the changes of a transaction, writing the state file of
+// every contract involved once. Each state file is replaced atomically.
+func (s *FileStore) Apply(changes []Change) error {
+	byContract := make(map[string][]Change)
+	var contractIDs []string
+	for _, change := range changes {
+		if err := validateKey(change.ContractID, change.Key); err != nil {
+			return err
+		}
+		if !change.Deleted && !utf8.Valid(change.Value) {
+			return fmt.Errorf("value of state key %v is not valid UTF-8", change.Key)
+		}
+		if _, ok := byContract[change.ContractID]; !ok {
+			contractIDs = append(contractIDs, change.ContractID)
+		}
+		byContract[change.ContractID] = append(byContract[change.ContractID], change)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, contractID := range contractIDs {
+		entries, err := s.load(contractID)
+		if err != nil {
+			return err
+		}
+		for _, change := range byContract[contractID] {
+			if change.Deleted {
+				delete(entries, change.Key)
+				continue
+			}
+			entries[change.Key] = string(change.Value)
+		}
+		if err := s.save(contractID, entries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Revision returns the number of writes made to the state of a contract
+// through the store
+func (s *FileStore) Revision(contractID string) uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.revisions[contractID]
+}
+
+func (s *FileStore) path(contractID string) (string, error) {
+	if !contractIDPattern.MatchString(contractID) {
+		return "", fmt.Errorf("contract ID %q cannot be used as a state file name", contractID)
+	}
+	return filepath.Join(s.dir, contractID+".json"), nil
+}
+
+// load reads the state of a contract. A contract without a state file has
+// an empty state.
+func (s *FileStore) load(contractID string) (map[string]string, error) {
+	statePath, err := s.path(contractID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]string)
+	stateBytes, err := os.ReadFile(statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state of contract %v: %w", contractID, err)
+	}
+
+	if err := json.Unmarshal(stateBytes, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %v: %w", statePath, err)
+	}
+	return entries, nil
+}
+
+// save writes the state of a contract through a temporary file, so that a
+// crash never leaves a partially written state file behind. The caller
+// must hold s.mu.
+func (s *FileStore) save(contractID string, entries map[string]string) error {
+	statePath, err := s.path(contractID)
+	if err != nil {
+		return err
+	}
+
+	stateBytes, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, contractID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write state of contract %v: %w", contractID, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(stateBytes); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state of contract %v: %w", contractID, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state of contract %v: %w", contractID, err)
+	}
+
+	if err := os.Rename(tmp.Name(), statePath); err != nil {
+		return err
+	}
+	s.revisions[contractID]++
+	return nil
+}
@@ -0,0 +1,54 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, store)
+}
+
+func TestFileStorePersists(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("counter", "count", []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The state is kept in <contract ID>.json and survives reopening
+	if _, err := os.Stat(filepath.Join(dir, "counter.json")); err != nil {
+		t.Fatalf("expected a state file, got %v", err)
+	}
+	reopened, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := reopened.Get("counter", "count"); err != nil || string(value) != "1" {
+		t.Errorf("expected count to be 1 after reopening, got %q, %v", value, err)
+	}
+}
+
+func TestFileStoreRejectsUnsafeInput(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, contractID := range []string{"../escape", "a/b", ".hidden"} {
+		if err := store.Set(contractID, "count", []byte("1")); err == nil {
+			t.Errorf("expected contract ID %q to be rejected", contractID)
+		}
+	}
+	if err := store.Set("counter", "count", []byte{0xff}); err == nil {
+		t.Error("expected a value which is not valid UTF-8 to be rejected")
+	}
+}
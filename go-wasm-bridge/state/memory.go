@@ -0,0 +1,143 @@
+package state
+
+import "sync"
+
+// MemoryStore is a StateStore which keeps state in memory. State is lost
+// when the process exits.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	contracts map[string]map[string][]byte
+	revisions map[string]uint64
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		contracts: make(map[string]map[string][]byte),
+		revisions: make(map[string]uint64),
+	}
+}
+
+func (s *MemoryStore) Get(contractID, key string) ([]byte, error) {
+	if err := validateKey(contractID, key); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, ok := s.contracts[contractID][key]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return append([]byte{}, value...), nil
+}
+
+func (s *MemoryStore) Set(contractID, key string, value []byte) error {
+	if err := validateKey(contractID, key); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, ok := s.contracts[contractID]
+	if !ok {
+		entries = make(map[string][]byte)
+		s.contracts[contractID] = entries
+	}
+	entries[key] = append([]byte{}, value...)
+	s.revisions[contractID]++
+	return nil
+}
+
+func (s *MemoryStore) Delete(contractID, key string) error {
+	if err := validateKey(contractID, key); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.contracts[contractID], key)
+	s.revisions[contractID]++
+	return nil
+}
+
+func (s *MemoryStore) Has(contractID, key string) (bool, error) {
+	if err := validateKey(contractID, key); err != nil {
+		return false, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.contracts[contractID][key]
+	return ok, nil
+}
+
+func (s *MemoryStore) Iterate(contractID, start, end string, fn func(key string, value []byte) bool) error {
+	// Copy the range so that fn may write to the store
+	s.mu.RLock()
+	entries := s.contracts[contractID]
+	keys := sortedKeys(entries, start, end)
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		values[i] = append([]byte{}, entries[key]...)
+	}
+	s.mu.RUnlock()
+
+	for i, key := range keys {
+		if !fn(key, values[i]) {
+			break
+		}
+	}
+	return nil
+}
+
+// Apply applies the changes of a transaction atomically
+func (s *MemoryStore) Apply(changes []Change) error {
+	for _, change := range changes {
+		if err := validateKey(change.ContractID, change.Key); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, change := range changes {
+		entries, ok := s.contracts[change.ContractID]
+		if !ok {
+			entries = make(map[string][]byte)
+			s.contracts[change.ContractID] = entries
+		}
+		s.revisions[change.ContractID]++
+		if change.Deleted {
+			delete(entries, change.Key)
+			continue
+		}
+		entries[change.Key] = append([]byte{}, change.Value...)
+	}
+	return nil
+}
+
+// Revision returns the number of writes made to the state of a contract
+func (s *MemoryStore) Revision(contractID string) uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.revisions[contractID]
+}
+
+// Export returns a copy of the state of a contract
+func (s *MemoryStore) Export(contractID string) (map[string][]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make(map[string][]byte, len(s.contracts[contractID]))
+	for key, value := range s.contracts[contractID] {
+		entries[key] = append([]byte{}, value...)
+	}
+	return entries, nil
+}
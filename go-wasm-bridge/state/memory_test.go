@@ -0,0 +1,27 @@
+package state
+
+import "testing"
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestMemoryStoreCopiesValues(t *testing.T) {
+	store := NewMemoryStore()
+
+	value := []byte("1")
+	if err := store.Set("counter", "count", value); err != nil {
+		t.Fatal(err)
+	}
+	value[0] = '2'
+
+	stored, err := store.Get("counter", "count")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored[0] = '3'
+
+	if stored, _ := store.Get("counter", "count"); string(stored) != "1" {
+		t.Errorf("expected the stored value to be unaffected by callers, got %q", stored)
+	}
+}
@@ -0,0 +1,287 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrQuotaExceeded is returned when a write would take the state of a
+// contract over its quota
+var ErrQuotaExceeded = errors.New("state quota exceeded")
+
+// Quota limits the size of the state of a contract. Zero values mean no
+// limit.
+type Quota struct {
+	// MaxBytes limits the total size of all keys and values
+	MaxBytes int64
+
+	// MaxKeys limits the number of keys
+	MaxKeys int64
+}
+
+// Usage is the size of the state of a contract
+type Usage struct {
+	Bytes int64 `json:"bytes"`
+	Keys  int64 `json:"keys"`
+}
+
+// QuotaError is returned for writes which would take the state of a
+// contract over its quota. Usage is the usage the writes would result in.
+type QuotaError struct {
+	ContractID string
+	Quota      Quota
+	Usage      Usage
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("state of contract %v would use %d bytes in %d keys, quota is %d bytes in %d keys",
+		e.ContractID, e.Usage.Bytes, e.Usage.Keys, e.Quota.MaxBytes, e.Quota.MaxKeys)
+}
+
+func (e *QuotaError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// QuotaChecker is implemented by stores which limit the state size of
+// contracts. Txn checks its write set with it on every write, so that the
+// contract is told about an exceeded quota by the write which exceeds it.
+type QuotaChecker interface {
+	// CheckChanges returns a QuotaError if applying changes would take a
+	// contract over its quota
+	CheckChanges(changes []Change) error
+}
+
+// QuotaStore is a StateStore which accounts the state size of every
+// contract on top of a base store, and rejects writes which exceed the
+// quota of a contract. Usage is counted from the base store the first time
+// a contract is written or asked about. If the base store is, or wraps, a
+// Revisioner, usage is counted again once writes which bypassed the
+// QuotaStore reach the state of the contract, like a restore of a
+// VersionedStore beneath it. Otherwise such writes are not accounted.
+type QuotaStore struct {
+	base StateStore
+
+	mu     sync.Mutex
+	quota  Quota
+	quotas map[string]Quota
+	usage  map[string]Usage
+
+	// revisions holds the revision of the base store each usage is
+	// accurate for
+	revisions map[string]uint64
+}
+
+// NewQuotaStore limits the state of every contract in base to quota
+func NewQuotaStore(base StateStore, quota Quota) *QuotaStore {
+	return &QuotaStore{
+		base:      base,
+		quota:     quota,
+		quotas:    make(map[string]Quota),
+		usage:     make(map[string]Usage),
+		revisions: make(map[string]uint64),
+	}
+}
+
+// SetQuota overrides the quota of a single contract
+func (s *QuotaStore) SetQuota(contractID string, quota Quota) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.quotas[contractID] = quota
+}
+
+// Quota returns the quota of a contract
+func (s *QuotaStore) Quota(contractID string) Quota {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.quotaOf(contractID)
+}
+
+// Usage returns the current state size of a contract
+func (s *QuotaStore) Usage(contractID string) (Usage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.usageOf(contractID)
+}
+
+// Unwrap returns the base store
+func (s *QuotaStore) Unwrap() StateStore {
+	return s.base
+}
+
+func (s *QuotaStore) Get(contractID, key string) ([]byte, error) {
+	return s.base.Get(contractID, key)
+}
+
+func (s *QuotaStore) Set(contractID, key string, value []byte) error {
+	return s.Apply([]Change{{ContractID: contractID, Key: key, Value: value}})
+}
+
+func (s *QuotaStore) Delete(contractID, key string) error {
+	return s.Apply([]Change{{ContractID: contractID, Key: key, Deleted: true}})
+}
+
+func (s *QuotaStore) Has(contractID, key string) (bool, error) {
+	return s.base.Has(contractID, key)
+}
+
+func (s *QuotaStore) Iterate(contractID, start, end string, fn func(key string, value []byte) bool) error {
+	return s.base.Iterate(contractID, start, end, fn)
+}
+
+// Export returns the state of a contract
+func (s *QuotaStore) Export(contractID string) (map[string][]byte, error) {
+	return exportState(s.base, contractID)
+}
+
+// Apply applies the changes if they keep every contract within its quota
+func (s *QuotaStore) Apply(changes []Change) error {
+	return s.apply(changes, func() error {
+		return applyChanges(s.base, changes)
+	})
+}
+
+// ApplyVersion is Apply for a base store which is a Versioner
+func (s *QuotaStore) ApplyVersion(changes []Change, blockID string) error {
+	return s.apply(changes, func() error {
+		if versioner, ok := s.base.(Versioner); ok {
+			return versioner.ApplyVersion(changes, blockID)
+		}
+		return applyChanges(s.base, changes)
+	})
+}
+
+func (s *QuotaStore) CheckChanges(changes []Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.check(changes)
+	return err
+}
+
+func (s *QuotaStore) apply(changes []Change, write func() error) error {
+	for _, change := range changes {
+		if err := validateKey(change.ContractID, change.Key); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	projected, err := s.check(changes)
+	if err != nil {
+		return err
+	}
+	if err := write(); err != nil {
+		return err
+	}
+	for contractID, usage := range projected {
+		s.usage[contractID] = usage
+		s.revisions[contractID], _ = s.revision(contractID)
+	}
+	return nil
+}
+
+// check returns the usage of every contract the changes touch after they
+// are applied. Writes which grow a contract beyond its quota are rejected,
+// but writes which shrink a contract over quota are not. The caller must
+// hold s.mu.
+func (s *QuotaStore) check(changes []Change) (map[string]Usage, error) {
+	projected := make(map[string]Usage)
+	// sizes holds the size of every key seen so far, -1 if it is not set
+	sizes := make(map[string]map[string]int64)
+
+	for _, change := range changes {
+		usage, ok := projected[change.ContractID]
+		if !ok {
+			current, err := s.usageOf(change.ContractID)
+			if err != nil {
+				return nil, err
+			}
+			usage = current
+			sizes[change.ContractID] = make(map[string]int64)
+		}
+
+		size, ok := sizes[change.ContractID][change.Key]
+		if !ok {
+			value, err := s.base.Get(change.ContractID, change.Key)
+			switch {
+			case errors.Is(err, ErrKeyNotFound):
+				size = -1
+			case err != nil:
+				return nil, err
+			default:
+				size = int64(len(change.Key) + len(value))
+			}
+		}
+		if size >= 0 {
+			usage.Keys--
+			usage.Bytes -= size
+		}
+
+		size = -1
+		if !change.Deleted {
+			size = int64(len(change.Key) + len(change.Value))
+			usage.Keys++
+			usage.Bytes += size
+		}
+		sizes[change.ContractID][change.Key] = size
+		projected[change.ContractID] = usage
+	}
+
+	for contractID, usage := range projected {
+		quota := s.quotaOf(contractID)
+		current := s.usage[contractID]
+		if (quota.MaxBytes > 0 && usage.Bytes > quota.MaxBytes && usage.Bytes > current.Bytes) ||
+			(quota.MaxKeys > 0 && usage.Keys > quota.MaxKeys && usage.Keys > current.Keys) {
+			return nil, &QuotaError{ContractID: contractID, Quota: quota, Usage: usage}
+		}
+	}
+	return projected, nil
+}
+
+// usageOf returns the usage of a contract, counting it from the base store
+// the first time and whenever the state has been written past the
+// QuotaStore. The caller must hold s.mu.
+func (s *QuotaStore) usageOf(contractID string) (Usage, error) {
+	revision, tracked := s.revision(contractID)
+	if usage, ok := s.usage[contractID]; ok && (!tracked || revision == s.revisions[contractID]) {
+		return usage, nil
+	}
+
+	var usage Usage
+	err := s.base.Iterate(contractID, "", "", func(key string, value []byte) bool {
+		usage.Keys++
+		usage.Bytes += int64(len(key) + len(value))
+		return true
+	})
+	if err != nil {
+		return Usage{}, err
+	}
+	s.usage[contractID] = usage
+	s.revisions[contractID] = revision
+	return usage, nil
+}
+
+// revision returns the revision of the state of a contract in the base
+// store, if the base store tracks revisions
+func (s *QuotaStore) revision(contractID string) (uint64, bool) {
+	revisioner, ok := Find[Revisioner](s.base)
+	if !ok {
+		return 0, false
+	}
+	return revisioner.Revision(contractID), true
+}
+
+func (s *QuotaStore) quotaOf(contractID string) Quota {
+	if quota, ok := s.quotas[contractID]; ok {
+		return quota
+	}
+	return s.quota
+}
+
+var _ Versioner = (*QuotaStore)(nil)
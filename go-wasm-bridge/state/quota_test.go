@@ -0,0 +1,93 @@
+package state
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQuotaStore(t *testing.T) {
+	testStore(t, NewQuotaStore(NewMemoryStore(), Quota{}))
+}
+
+func TestQuotaExceeded(t *testing.T) {
+	store := NewQuotaStore(NewMemoryStore(), Quota{MaxBytes: 8})
+	store.SetQuota("large", Quota{MaxBytes: 64})
+
+	err := store.Set("counter", "owner", []byte("alice"))
+	var quotaErr *QuotaError
+	if !errors.Is(err, ErrQuotaExceeded) || !errors.As(err, &quotaErr) {
+		t.Fatalf("expected a QuotaError, got %v", err)
+	}
+	if quotaErr.Usage.Bytes != 10 || quotaErr.Quota.MaxBytes != 8 {
+		t.Errorf("unexpected quota error %+v", quotaErr)
+	}
+
+	if err := store.Set("large", "owner", []byte("alice")); err != nil {
+		t.Errorf("expected the larger quota of the contract to apply, got %v", err)
+	}
+}
+
+func TestQuotaUsage(t *testing.T) {
+	store := NewQuotaStore(NewMemoryStore(), Quota{MaxKeys: 2})
+	for _, key := range []string{"a", "b"} {
+		if err := store.Set("counter", key, []byte("1")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Set("counter", "c", []byte("1")); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("expected ErrQuotaExceeded for a third key, got %v", err)
+	}
+
+	// Overwriting a key keeps the key count, deleting one frees it
+	if err := store.Set("counter", "a", []byte("10")); err != nil {
+		t.Fatal(err)
+	}
+	if usage, err := store.Usage("counter"); err != nil || usage != (Usage{Bytes: 5, Keys: 2}) {
+		t.Errorf("expected 5 bytes in 2 keys, got %+v, %v", usage, err)
+	}
+	if err := store.Delete("counter", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("counter", "c", []byte("1")); err != nil {
+		t.Errorf("expected the deleted key to free quota, got %v", err)
+	}
+}
+
+func TestQuotaRecountsBypassingWrites(t *testing.T) {
+	base := NewMemoryStore()
+	store := NewQuotaStore(base, Quota{MaxKeys: 2})
+	if err := store.Set("counter", "a", []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A write to the base store is counted once its revision changes
+	if err := base.Set("counter", "b", []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	if usage, err := store.Usage("counter"); err != nil || usage.Keys != 2 {
+		t.Errorf("expected the bypassing write to be counted, got %+v, %v", usage, err)
+	}
+	if err := store.Set("counter", "c", []byte("1")); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded, got %v", err)
+	}
+}
+
+func TestTxnChecksQuota(t *testing.T) {
+	store := NewQuotaStore(NewMemoryStore(), Quota{MaxKeys: 1})
+	txn := NewTxn(store)
+	if err := txn.Set("counter", "a", []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The write over quota is rejected, the buffered one is kept
+	if err := txn.Set("counter", "b", []byte("1")); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("expected ErrQuotaExceeded, got %v", err)
+	}
+	changes, err := txn.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Key != "a" {
+		t.Errorf("expected only key a to be committed, got %v", changes)
+	}
+}
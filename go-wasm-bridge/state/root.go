@@ -0,0 +1,72 @@
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrExportUnsupported is returned when a store cannot enumerate the state
+// of a contract
+var ErrExportUnsupported = errors.New("state store does not support export")
+
+// ErrRootMismatch is returned when a state export does not hash to the
+// expected state root
+var ErrRootMismatch = errors.New("state root mismatch")
+
+// Exporter is implemented by stores which can enumerate the state of a
+// contract
+type Exporter interface {
+	// Export returns every key of the contract with its value
+	Export(contractID string) (map[string][]byte, error)
+}
+
+// ComputeRoot returns the state root of a contract state: the hex encoded
+// SHA-256 hash over all keys in sorted order, each key and value prefixed
+// with its length. It does not depend on the store the state was read from.
+func ComputeRoot(entries map[string][]byte) string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	var length [8]byte
+	for _, key := range keys {
+		binary.BigEndian.PutUint64(length[:], uint64(len(key)))
+		hash.Write(length[:])
+		hash.Write([]byte(key))
+
+		binary.BigEndian.PutUint64(length[:], uint64(len(entries[key])))
+		hash.Write(length[:])
+		hash.Write(entries[key])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Root returns the state root of a contract
+func Root(store StateStore, contractID string) (string, error) {
+	exporter, ok := Find[Exporter](store)
+	if !ok {
+		return "", ErrExportUnsupported
+	}
+
+	entries, err := exporter.Export(contractID)
+	if err != nil {
+		return "", err
+	}
+	return ComputeRoot(entries), nil
+}
+
+// VerifyRoot recomputes the state root of a state export and checks it
+// against root
+func VerifyRoot(entries map[string][]byte, root string) error {
+	if computed := ComputeRoot(entries); computed != root {
+		return fmt.Errorf("%w: expected %v, computed %v", ErrRootMismatch, root, computed)
+	}
+	return nil
+}
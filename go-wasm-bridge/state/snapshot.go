@@ -0,0 +1,87 @@
+package state
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Snapshot is the full state of a contract in its JSON export format
+type Snapshot struct {
+	ContractID string            `json:"contract_id"`
+	Root       string            `json:"root"`
+	Entries    map[string]string `json:"entries"`
+}
+
+// NewSnapshot exports the state of a contract from store. Values must be
+// valid UTF-8, as they are exported as JSON strings.
+func NewSnapshot(store StateStore, contractID string) (*Snapshot, error) {
+	entries, err := exportState(store, contractID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{
+		ContractID: contractID,
+		Root:       ComputeRoot(entries),
+		Entries:    make(map[string]string, len(entries)),
+	}
+	for key, value := range entries {
+		if !utf8.Valid(value) {
+			return nil, fmt.Errorf("value of state key %q of contract %v is not valid UTF-8", key, contractID)
+		}
+		snapshot.Entries[key] = string(value)
+	}
+	return snapshot, nil
+}
+
+// Verify checks the entries of the snapshot against its root. A snapshot
+// without a root is not checked.
+func (s *Snapshot) Verify() error {
+	if s.Root == "" {
+		return nil
+	}
+	return VerifyRoot(s.entries(), s.Root)
+}
+
+// Restore replaces the state of contractID in store with the entries of
+// the snapshot, see ReplaceState. contractID may differ from the contract
+// the snapshot was taken of.
+func (s *Snapshot) Restore(store StateStore, contractID string) ([]Change, error) {
+	if err := s.Verify(); err != nil {
+		return nil, err
+	}
+	return ReplaceState(store, contractID, s.entries())
+}
+
+func (s *Snapshot) entries() map[string][]byte {
+	entries := make(map[string][]byte, len(s.Entries))
+	for key, value := range s.Entries {
+		entries[key] = []byte(value)
+	}
+	return entries
+}
+
+// ReplaceState replaces the state of a contract with entries, and returns
+// the changes it made. The changes are applied atomically if store is a
+// BatchStore.
+func ReplaceState(store StateStore, contractID string, entries map[string][]byte) ([]Change, error) {
+	for key := range entries {
+		if err := validateKey(contractID, key); err != nil {
+			return nil, err
+		}
+	}
+
+	current, err := exportState(store, contractID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := diffState(contractID, current, entries)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	if err := applyChanges(store, changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
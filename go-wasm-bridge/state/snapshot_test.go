@@ -0,0 +1,83 @@
+package state
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	store := NewMemoryStore()
+	for key, value := range map[string]string{"count": "1", "owner": "alice"} {
+		if err := store.Set("counter", key, []byte(value)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Set("copy", "stale", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := NewSnapshot(store, "counter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := snapshot.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Restoring into another contract replaces its state
+	changes, err := snapshot.Restore(store, "copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 3 {
+		t.Errorf("expected 3 changes, got %v", changes)
+	}
+	copied, err := NewSnapshot(store, "copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(copied.Entries, snapshot.Entries) || copied.Root != snapshot.Root {
+		t.Errorf("expected the restored state to equal the snapshot, got %+v", copied)
+	}
+
+	// Restoring an unchanged state makes no changes
+	if changes, err := snapshot.Restore(store, "copy"); err != nil || len(changes) != 0 {
+		t.Errorf("expected no changes, got %v, %v", changes, err)
+	}
+}
+
+func TestSnapshotRootMismatch(t *testing.T) {
+	store := NewMemoryStore()
+	if err := store.Set("counter", "count", []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := NewSnapshot(store, "counter")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot.Entries["count"] = "2"
+	if _, err := snapshot.Restore(store, "copy"); !errors.Is(err, ErrRootMismatch) {
+		t.Fatalf("expected ErrRootMismatch, got %v", err)
+	}
+	if ok, _ := store.Has("copy", "count"); ok {
+		t.Error("expected a snapshot with a wrong root not to be restored")
+	}
+
+	// A snapshot without a root is not checked
+	snapshot.Root = ""
+	if _, err := snapshot.Restore(store, "copy"); err != nil {
+		t.Errorf("expected a snapshot without a root to be restored, got %v", err)
+	}
+}
+
+func TestSnapshotRejectsInvalidUTF8(t *testing.T) {
+	store := NewMemoryStore()
+	if err := store.Set("counter", "count", []byte{0xff}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSnapshot(store, "counter"); err == nil {
+		t.Error("expected a value which is not valid UTF-8 to be rejected")
+	}
+}
@@ -0,0 +1,102 @@
+// Package state implements the persistent key-value state of contracts
+package state
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrKeyNotFound is returned when a key has no value in the state of a
+// contract
+var ErrKeyNotFound = errors.New("key not found")
+
+// StateStore persists the key-value state of contracts. Every contract
+// has its own key space, identified by its contract ID. Implementations
+// must be safe for concurrent use.
+type StateStore interface {
+	// Get returns the value of key, or ErrKeyNotFound
+	Get(contractID, key string) ([]byte, error)
+
+	// Set stores value under key
+	Set(contractID, key string, value []byte) error
+
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(contractID, key string) error
+
+	// Has reports whether key has a value
+	Has(contractID, key string) (bool, error)
+
+	// Iterate calls fn for every key in [start, end) in ascending order,
+	// until fn returns false. An empty end means no upper bound.
+	Iterate(contractID, start, end string, fn func(key string, value []byte) bool) error
+}
+
+// PrefixEnd returns the smallest key greater than every key starting with
+// prefix, for use as the end of an Iterate range. It returns "" if there
+// is no such key.
+func PrefixEnd(prefix string) string {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1])
+		}
+	}
+	return ""
+}
+
+// inRange reports whether key lies in [start, end)
+func inRange(key, start, end string) bool {
+	return key >= start && (end == "" || key < end)
+}
+
+// sortedKeys returns the keys of entries in [start, end) in ascending order
+func sortedKeys[V any](entries map[string]V, start, end string) []string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		if inRange(key, start, end) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func validateKey(contractID, key string) error {
+	if contractID == "" {
+		return errors.New("contract ID must not be empty")
+	}
+	if key == "" {
+		return fmt.Errorf("state key of contract %v must not be empty", contractID)
+	}
+	return nil
+}
+
+// Revisioner is implemented by stores which count the writes to the state
+// of every contract. The revision of a contract changes whenever a write
+// reaches its state, so that values derived from the state, like its
+// root, can be reused while the revision is unchanged.
+type Revisioner interface {
+	// Revision returns the current revision of the state of a contract
+	Revision(contractID string) uint64
+}
+
+// Find returns the first store of type T in the chain of stores starting
+// at store, following stores which wrap another one through an
+// Unwrap() StateStore method
+func Find[T any](store StateStore) (T, bool) {
+	for store != nil {
+		if found, ok := store.(T); ok {
+			return found, true
+		}
+		wrapper, ok := store.(interface{ Unwrap() StateStore })
+		if !ok {
+			break
+		}
+		store = wrapper.Unwrap()
+	}
+
+	var zero T
+	return zero, false
+}
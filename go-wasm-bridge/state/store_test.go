@@ -0,0 +1,78 @@
+package state
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testStore runs the behaviour every StateStore must have against store
+func testStore(t *testing.T, store StateStore) {
+	t.Helper()
+
+	if _, err := store.Get("counter", "count"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound for a missing key, got %v", err)
+	}
+	if ok, err := store.Has("counter", "count"); err != nil || ok {
+		t.Errorf("expected a missing key not to be set, got %v, %v", ok, err)
+	}
+
+	if err := store.Set("counter", "count", []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := store.Get("counter", "count"); err != nil || string(value) != "1" {
+		t.Errorf("expected count to be 1, got %q, %v", value, err)
+	}
+	if ok, err := store.Has("counter", "count"); err != nil || !ok {
+		t.Errorf("expected count to be set, got %v, %v", ok, err)
+	}
+
+	// Every contract has its own key space
+	if ok, err := store.Has("other", "count"); err != nil || ok {
+		t.Errorf("expected the key of another contract to be invisible, got %v, %v", ok, err)
+	}
+
+	if err := store.Delete("counter", "count"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("counter", "count"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected count to be deleted, got %v", err)
+	}
+	if err := store.Delete("counter", "count"); err != nil {
+		t.Errorf("expected deleting a missing key to succeed, got %v", err)
+	}
+
+	// Iterate visits the keys in [start, end) in order, and stops when fn
+	// returns false
+	for _, key := range []string{"item:3", "item:1", "item:2", "other"} {
+		if err := store.Set("counter", key, []byte(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var keys []string
+	err := store.Iterate("counter", "item:", "item:3", func(key string, value []byte) bool {
+		if string(value) != key {
+			t.Errorf("expected the value of %v to be %v, got %q", key, key, value)
+		}
+		keys = append(keys, key)
+		return true
+	})
+	if err != nil || !reflect.DeepEqual(keys, []string{"item:1", "item:2"}) {
+		t.Errorf("expected to iterate item:1 and item:2, got %v, %v", keys, err)
+	}
+	keys = nil
+	err = store.Iterate("counter", "item:2", "", func(key string, value []byte) bool {
+		keys = append(keys, key)
+		return key < "item:3"
+	})
+	if err != nil || !reflect.DeepEqual(keys, []string{"item:2", "item:3"}) {
+		t.Errorf("expected the iteration to stop at item:3, got %v, %v", keys, err)
+	}
+
+	if err := store.Set("", "count", []byte("1")); err == nil {
+		t.Error("expected an empty contract ID to be rejected")
+	}
+	if err := store.Set("counter", "", []byte("1")); err == nil {
+		t.Error("expected an empty key to be rejected")
+	}
+}
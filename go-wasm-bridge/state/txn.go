@@ -0,0 +1,440 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrConflict is returned by Txn.Commit when a key the transaction read
+// was changed in the base store before the transaction committed
+var ErrConflict = errors.New("state changed since it was read")
+
+// Change is a single write of a committed transaction
+type Change struct {
+	ContractID string `json:"contract_id"`
+	Key        string `json:"key"`
+	Value      []byte `json:"value,omitempty"`
+	Deleted    bool   `json:"deleted,omitempty"`
+}
+
+// BatchStore is implemented by stores which can apply the changes of a
+// transaction atomically
+type BatchStore interface {
+	StateStore
+	Apply(changes []Change) error
+}
+
+// Txn is a StateStore which buffers writes in a write set on top of a
+// base store. Reads see the buffered writes. Nothing reaches the base
+// store until Commit.
+//
+// Reads which reach the base store, including the ranges covered by
+// Iterate, are recorded in a read set. Commit validates it, failing with
+// ErrConflict if any of these keys changed in the meantime, so that a
+// transaction never commits writes derived from stale reads. Commits of
+// transactions on the same base store are serialized, which makes
+// validating and applying the writes atomic with respect to each other.
+// Writes which bypass transactions are only detected if they reach the
+// base store before the commit starts.
+type Txn struct {
+	base    StateStore
+	blockID string
+
+	mu     sync.RWMutex
+	writes map[string]map[string]*Change
+	reads  map[string]map[string]*readEntry
+	ranges []*rangeRead
+}
+
+// readEntry is what a transaction observed of a key of the base store
+type readEntry struct {
+	found bool
+	// value is only known if the key was read with Get rather than Has
+	value     []byte
+	valueRead bool
+}
+
+// rangeRead is a range of keys of the base store a transaction iterated,
+// with the entries it saw there
+type rangeRead struct {
+	contractID string
+	start, end string
+	entries    []rangeEntry
+}
+
+type rangeEntry struct {
+	key   string
+	value []byte
+}
+
+// NewTxn begins a transaction on base. base must be comparable, as
+// pointers are, since commits are serialized per base store.
+func NewTxn(base StateStore) *Txn {
+	return &Txn{
+		base:   base,
+		writes: make(map[string]map[string]*Change),
+		reads:  make(map[string]map[string]*readEntry),
+	}
+}
+
+// commitLocks holds a *sync.Mutex per base store, see Txn
+var commitLocks sync.Map
+
+func commitLock(base StateStore) *sync.Mutex {
+	lock, _ := commitLocks.LoadOrStore(base, &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
+
+// WithBlockID tags the version Commit makes with the Rubix block the
+// transaction belongs to, if the base store is a Versioner
+func (t *Txn) WithBlockID(blockID string) *Txn {
+	t.blockID = blockID
+	return t
+}
+
+func (t *Txn) Get(contractID, key string) ([]byte, error) {
+	if err := validateKey(contractID, key); err != nil {
+		return nil, err
+	}
+
+	t.mu.RLock()
+	change, ok := t.writes[contractID][key]
+	t.mu.RUnlock()
+	if !ok {
+		value, err := t.base.Get(contractID, key)
+		if err == nil || errors.Is(err, ErrKeyNotFound) {
+			t.read(contractID, key, &readEntry{found: err == nil, value: value, valueRead: true})
+		}
+		return value, err
+	}
+	if change.Deleted {
+		return nil, ErrKeyNotFound
+	}
+	return append([]byte{}, change.Value...), nil
+}
+
+func (t *Txn) Set(contractID, key string, value []byte) error {
+	if err := validateKey(contractID, key); err != nil {
+		return err
+	}
+
+	return t.write(&Change{ContractID: contractID, Key: key, Value: append([]byte{}, value...)})
+}
+
+func (t *Txn) Delete(contractID, key string) error {
+	if err := validateKey(contractID, key); err != nil {
+		return err
+	}
+
+	return t.write(&Change{ContractID: contractID, Key: key, Deleted: true})
+}
+
+func (t *Txn) Has(contractID, key string) (bool, error) {
+	if err := validateKey(contractID, key); err != nil {
+		return false, err
+	}
+
+	t.mu.RLock()
+	change, ok := t.writes[contractID][key]
+	t.mu.RUnlock()
+	if !ok {
+		found, err := t.base.Has(contractID, key)
+		if err == nil {
+			t.read(contractID, key, &readEntry{found: found})
+		}
+		return found, err
+	}
+	return !change.Deleted, nil
+}
+
+// read records the first observation of a key of the base store. A later
+// Get completes an entry recorded by Has with the value.
+func (t *Txn) read(contractID, key string, entry *readEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entries, ok := t.reads[contractID]
+	if !ok {
+		entries = make(map[string]*readEntry)
+		t.reads[contractID] = entries
+	}
+	prev, ok := entries[key]
+	if !ok {
+		entry.value = append([]byte{}, entry.value...)
+		entries[key] = entry
+		return
+	}
+	if !prev.valueRead && entry.valueRead && prev.found == entry.found {
+		prev.value = append([]byte{}, entry.value...)
+		prev.valueRead = true
+	}
+}
+
+// validate checks that the keys in the read set are unchanged in the base
+// store
+func (t *Txn) validate() error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for contractID, entries := range t.reads {
+		for key, entry := range entries {
+			var found bool
+			var value []byte
+			var err error
+			if entry.valueRead {
+				value, err = t.base.Get(contractID, key)
+				found = err == nil
+				if errors.Is(err, ErrKeyNotFound) {
+					err = nil
+				}
+			} else {
+				found, err = t.base.Has(contractID, key)
+			}
+			if err != nil {
+				return err
+			}
+
+			if found != entry.found || (entry.valueRead && !bytes.Equal(value, entry.value)) {
+				return fmt.Errorf("%w: key %v of contract %v", ErrConflict, key, contractID)
+			}
+		}
+	}
+
+	for _, r := range t.ranges {
+		var entries []rangeEntry
+		err := t.base.Iterate(r.contractID, r.start, r.end, func(key string, value []byte) bool {
+			entries = append(entries, rangeEntry{key: key, value: value})
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		if !equalEntries(entries, r.entries) {
+			return fmt.Errorf("%w: keys in [%q, %q) of contract %v", ErrConflict, r.start, r.end, r.contractID)
+		}
+	}
+	return nil
+}
+
+// Iterate merges the buffered writes into the iteration of the base store.
+// The range of the base store it covered, up to the key at which fn
+// stopped, is recorded in the read set, so that keys which are changed,
+// added or removed in it before Commit make the transaction conflict.
+func (t *Txn) Iterate(contractID, start, end string, fn func(key string, value []byte) bool) error {
+	t.mu.RLock()
+	writes := make([]Change, 0, len(t.writes[contractID]))
+	for _, key := range sortedKeys(t.writes[contractID], start, end) {
+		writes = append(writes, *t.writes[contractID][key])
+	}
+	t.mu.RUnlock()
+
+	stopped, lastKey := false, ""
+	// emit hands a buffered write to fn, skipping deletions
+	emit := func(change Change) bool {
+		if change.Deleted {
+			return true
+		}
+		lastKey = change.Key
+		stopped = !fn(change.Key, append([]byte{}, change.Value...))
+		return !stopped
+	}
+
+	var seen []rangeEntry
+	err := t.base.Iterate(contractID, start, end, func(key string, value []byte) bool {
+		seen = append(seen, rangeEntry{key: key, value: append([]byte{}, value...)})
+
+		for len(writes) > 0 && writes[0].Key < key {
+			if !emit(writes[0]) {
+				return false
+			}
+			writes = writes[1:]
+		}
+		if len(writes) > 0 && writes[0].Key == key {
+			change := writes[0]
+			writes = writes[1:]
+			return emit(change)
+		}
+		lastKey = key
+		stopped = !fn(key, value)
+		return !stopped
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, change := range writes {
+		if stopped || !emit(change) {
+			break
+		}
+	}
+
+	// Only the keys up to the one fn stopped at were observed
+	if stopped {
+		end = lastKey + "\x00"
+		for len(seen) > 0 && seen[len(seen)-1].key >= end {
+			seen = seen[:len(seen)-1]
+		}
+	}
+	t.mu.Lock()
+	t.ranges = append(t.ranges, &rangeRead{contractID: contractID, start: start, end: end, entries: seen})
+	t.mu.Unlock()
+	return nil
+}
+
+// equalEntries reports whether two iterations saw the same entries
+func equalEntries(a, b []rangeEntry) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].key != b[i].key || !bytes.Equal(a[i].value, b[i].value) {
+			return false
+		}
+	}
+	return true
+}
+
+// write buffers a change. A write which takes the contract over its quota
+// in the base store is rejected, see QuotaChecker.
+func (t *Txn) write(change *Change) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entries, ok := t.writes[change.ContractID]
+	if !ok {
+		entries = make(map[string]*Change)
+		t.writes[change.ContractID] = entries
+	}
+	previous, buffered := entries[change.Key]
+	entries[change.Key] = change
+
+	checker, ok := Find[QuotaChecker](t.base)
+	if !ok || change.Deleted {
+		return nil
+	}
+	changes := make([]Change, 0, len(entries))
+	for _, key := range sortedKeys(entries, "", "") {
+		changes = append(changes, *entries[key])
+	}
+	if err := checker.CheckChanges(changes); err != nil {
+		if buffered {
+			entries[change.Key] = previous
+		} else {
+			delete(entries, change.Key)
+		}
+		return err
+	}
+	return nil
+}
+
+// Export returns the state of a contract in the base store with the
+// buffered writes applied
+func (t *Txn) Export(contractID string) (map[string][]byte, error) {
+	exporter, ok := Find[Exporter](t.base)
+	if !ok {
+		return nil, ErrExportUnsupported
+	}
+
+	entries, err := exporter.Export(contractID)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for key, change := range t.writes[contractID] {
+		if change.Deleted {
+			delete(entries, key)
+			continue
+		}
+		entries[key] = append([]byte{}, change.Value...)
+	}
+	return entries, nil
+}
+
+// Changes returns the buffered writes, sorted by contract ID and key
+func (t *Txn) Changes() []Change {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var changes []Change
+	for _, entries := range t.writes {
+		for _, change := range entries {
+			changes = append(changes, *change)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].ContractID != changes[j].ContractID {
+			return changes[i].ContractID < changes[j].ContractID
+		}
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+// Commit validates the read set and writes the buffered writes to the
+// base store, returning them. Writes are applied atomically if the base
+// store is a BatchStore. A transaction without writes commits nothing and
+// never conflicts.
+func (t *Txn) Commit() ([]Change, error) {
+	changes := t.Changes()
+	if len(changes) == 0 {
+		t.Rollback()
+		return nil, nil
+	}
+
+	lock := commitLock(t.base)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if versioner, ok := t.base.(Versioner); ok {
+		err = versioner.ApplyVersion(changes, t.blockID)
+	} else {
+		err = applyChanges(t.base, changes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t.Rollback()
+	return changes, nil
+}
+
+// Rollback discards the buffered writes and the read set
+func (t *Txn) Rollback() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.writes = make(map[string]map[string]*Change)
+	t.reads = make(map[string]map[string]*readEntry)
+	t.ranges = nil
+}
+
+// applyChanges writes changes to store, atomically if it is a BatchStore
+func applyChanges(store StateStore, changes []Change) error {
+	if batch, ok := store.(BatchStore); ok {
+		return batch.Apply(changes)
+	}
+	for _, change := range changes {
+		if err := applyChange(store, change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyChange(store StateStore, change Change) error {
+	if change.Deleted {
+		return store.Delete(change.ContractID, change.Key)
+	}
+	return store.Set(change.ContractID, change.Key, change.Value)
+}
@@ -0,0 +1,256 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestTxnBuffersWrites(t *testing.T) {
+	base := NewMemoryStore()
+	if err := base.Set("counter", "count", []byte("0")); err != nil {
+		t.Fatal(err)
+	}
+	txn := NewTxn(base)
+
+	if err := txn.Set("counter", "count", []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Delete("counter", "owner"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The transaction sees its writes, the base store does not
+	if value, err := txn.Get("counter", "count"); err != nil || string(value) != "1" {
+		t.Errorf("expected the transaction to see count 1, got %q, %v", value, err)
+	}
+	if ok, err := txn.Has("counter", "owner"); err != nil || ok {
+		t.Errorf("expected the deleted key to be gone in the transaction, got %v, %v", ok, err)
+	}
+	if value, err := base.Get("counter", "count"); err != nil || string(value) != "0" {
+		t.Errorf("expected the base store to keep count 0, got %q, %v", value, err)
+	}
+
+	changes, err := txn.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].Key != "count" || changes[1].Key != "owner" || !changes[1].Deleted {
+		t.Errorf("unexpected changes %+v", changes)
+	}
+	if value, err := base.Get("counter", "count"); err != nil || string(value) != "1" {
+		t.Errorf("expected count 1 to be committed, got %q, %v", value, err)
+	}
+	if len(txn.Changes()) != 0 {
+		t.Error("expected the write set to be empty after Commit")
+	}
+}
+
+func TestTxnRollback(t *testing.T) {
+	base := NewMemoryStore()
+	txn := NewTxn(base)
+
+	if err := txn.Set("counter", "count", []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	txn.Rollback()
+
+	if changes, err := txn.Commit(); err != nil || len(changes) != 0 {
+		t.Errorf("expected nothing to commit, got %+v, %v", changes, err)
+	}
+	if ok, _ := base.Has("counter", "count"); ok {
+		t.Error("expected the rolled back write not to reach the base store")
+	}
+}
+
+func TestTxnConflicts(t *testing.T) {
+	tests := []struct {
+		name string
+		read func(txn *Txn) error
+	}{
+		{"get", func(txn *Txn) error {
+			_, err := txn.Get("counter", "count")
+			return err
+		}},
+		{"has", func(txn *Txn) error {
+			_, err := txn.Has("counter", "owner")
+			return err
+		}},
+		{"get missing", func(txn *Txn) error {
+			if _, err := txn.Get("counter", "owner"); !errors.Is(err, ErrKeyNotFound) {
+				return err
+			}
+			return nil
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := NewMemoryStore()
+			if err := base.Set("counter", "count", []byte("0")); err != nil {
+				t.Fatal(err)
+			}
+			txn := NewTxn(base)
+			if err := tt.read(txn); err != nil {
+				t.Fatal(err)
+			}
+			if err := txn.Set("counter", "total", []byte("1")); err != nil {
+				t.Fatal(err)
+			}
+
+			// Another writer changes what the transaction read
+			if err := base.Set("counter", "count", []byte("5")); err != nil {
+				t.Fatal(err)
+			}
+			if err := base.Set("counter", "owner", []byte("alice")); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := txn.Commit(); !errors.Is(err, ErrConflict) {
+				t.Fatalf("expected ErrConflict, got %v", err)
+			}
+			if ok, _ := base.Has("counter", "total"); ok {
+				t.Error("expected the conflicting write not to be committed")
+			}
+		})
+	}
+}
+
+func TestTxnWithoutConflict(t *testing.T) {
+	base := NewMemoryStore()
+	txn := NewTxn(base)
+	if _, err := txn.Get("counter", "count"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatal(err)
+	}
+	if err := txn.Set("counter", "count", []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Keys the transaction did not read may change
+	if err := base.Set("counter", "owner", []byte("alice")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Commit(); err != nil {
+		t.Fatalf("expected the commit to succeed, got %v", err)
+	}
+
+	// A transaction without writes never conflicts
+	readOnly := NewTxn(base)
+	if _, err := readOnly.Get("counter", "count"); err != nil {
+		t.Fatal(err)
+	}
+	if err := base.Set("counter", "count", []byte("2")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readOnly.Commit(); err != nil {
+		t.Errorf("expected a read-only commit to succeed, got %v", err)
+	}
+}
+
+func TestTxnIterationConflicts(t *testing.T) {
+	base := NewMemoryStore()
+	for _, key := range []string{"item:1", "item:2", "item:4"} {
+		if err := base.Set("counter", key, []byte("1")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// iterate reads the items up to item:2 and writes their count
+	iterate := func() *Txn {
+		txn := NewTxn(base)
+		count := 0
+		err := txn.Iterate("counter", "item:", "item;", func(key string, value []byte) bool {
+			count++
+			return key < "item:2"
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := txn.Set("counter", "count", []byte(fmt.Sprint(count))); err != nil {
+			t.Fatal(err)
+		}
+		return txn
+	}
+
+	// Keys past the one the iteration stopped at may change
+	txn := iterate()
+	if err := base.Set("counter", "item:5", []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Commit(); err != nil {
+		t.Fatalf("expected the commit to succeed, got %v", err)
+	}
+
+	// A key added within the iterated range conflicts
+	txn = iterate()
+	if err := base.Set("counter", "item:10", []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Commit(); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict for an added key, got %v", err)
+	}
+
+	// So does a changed or removed one
+	txn = iterate()
+	if err := base.Delete("counter", "item:1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Commit(); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict for a removed key, got %v", err)
+	}
+}
+
+func TestTxnConcurrentIncrements(t *testing.T) {
+	base := NewMemoryStore()
+	if err := base.Set("counter", "count", []byte("0")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every transaction reads count and writes it back incremented, so at
+	// most one of the transactions which read the same value commits
+	const workers = 8
+	var wg, read sync.WaitGroup
+	var mu sync.Mutex
+	committed := 0
+	start := make(chan struct{})
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		read.Add(1)
+		go func() {
+			defer wg.Done()
+			txn := NewTxn(base)
+			value, err := txn.Get("counter", "count")
+			read.Done()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			<-start
+			if err := txn.Set("counter", "count", append(value, '+')); err != nil {
+				t.Error(err)
+				return
+			}
+			_, err = txn.Commit()
+			if err != nil && !errors.Is(err, ErrConflict) {
+				t.Error(err)
+				return
+			}
+			if err == nil {
+				mu.Lock()
+				committed++
+				mu.Unlock()
+			}
+		}()
+	}
+	read.Wait()
+	close(start)
+	wg.Wait()
+
+	if committed != 1 {
+		t.Errorf("expected exactly one commit, got %d", committed)
+	}
+	if value, _ := base.Get("counter", "count"); string(value) != "0+" {
+		t.Errorf("expected count 0+, got %q", value)
+	}
+}
@@ -0,0 +1,443 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrVersionNotFound is returned when a version of a contract state is
+// unknown or has been pruned
+var ErrVersionNotFound = errors.New("state version not found")
+
+// Version describes one version of the state of a contract. Every batch of
+// changes applied to a VersionedStore becomes a new version of each
+// contract it touches, numbered from 1 per contract.
+type Version struct {
+	// Seq numbers the versions of a contract. It counts the batches of
+	// changes committed to its state, not calls: a call which fails or
+	// does not write state makes no version, while restores and writes
+	// made to the VersionedStore outside of calls do.
+	Seq     uint64    `json:"seq"`
+	BlockID string    `json:"block_id,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// RetentionPolicy limits the history a VersionedStore keeps per contract.
+// Zero values mean no limit.
+type RetentionPolicy struct {
+	// MaxVersions is the number of most recent versions to keep
+	MaxVersions int
+
+	// MaxAge is how long a version is kept after it was made
+	MaxAge time.Duration
+}
+
+// Versioner is implemented by stores which keep a version history. Txn
+// commits through ApplyVersion, so that the version is tagged with the
+// Rubix block of the call.
+type Versioner interface {
+	BatchStore
+	ApplyVersion(changes []Change, blockID string) error
+}
+
+// VersionedStore is a StateStore which keeps the history of the state of
+// each contract on top of a base store. It records, for every version, the
+// previous values of the keys it changed, so that older states can be read
+// back and restored. The history is kept in memory, and persisted if the
+// store is opened with OpenVersionedStore. Writes made to the base store
+// directly are not recorded.
+type VersionedStore struct {
+	base      StateStore
+	retention RetentionPolicy
+	log       *versionLog
+
+	mu        sync.RWMutex
+	contracts map[string]*history
+}
+
+// history is the version history of one contract
+type history struct {
+	lastSeq  uint64
+	versions []*versionRecord
+}
+
+// versionRecord is a version with the changes that undo it
+type versionRecord struct {
+	Version
+	undo []Change
+}
+
+// NewVersionedStore starts keeping the history of the state in base. The
+// current state of base is version 0 of every contract.
+func NewVersionedStore(base StateStore, retention RetentionPolicy) *VersionedStore {
+	return &VersionedStore{
+		base:      base,
+		retention: retention,
+		contracts: make(map[string]*history),
+	}
+}
+
+// OpenVersionedStore is NewVersionedStore with the history persisted in
+// logDir, which is created if it does not exist. The history of every
+// contract is appended to <contract ID>.versions.jsonl as versions are
+// made, and loaded back when the store is opened again. logDir is
+// typically the state directory of a FileStore base, so that the history
+// is kept next to the state it belongs to. The history must have been
+// recorded on top of the current state of base.
+func OpenVersionedStore(base StateStore, retention RetentionPolicy, logDir string) (*VersionedStore, error) {
+	log, err := newVersionLog(logDir)
+	if err != nil {
+		return nil, err
+	}
+	contracts, err := log.load()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &VersionedStore{
+		base:      base,
+		retention: retention,
+		log:       log,
+		contracts: contracts,
+	}
+	s.pruneAll(time.Now())
+	return s, nil
+}
+
+// Unwrap returns the base store
+func (s *VersionedStore) Unwrap() StateStore {
+	return s.base
+}
+
+func (s *VersionedStore) Get(contractID, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.base.Get(contractID, key)
+}
+
+func (s *VersionedStore) Set(contractID, key string, value []byte) error {
+	return s.ApplyVersion([]Change{{ContractID: contractID, Key: key, Value: value}}, "")
+}
+
+func (s *VersionedStore) Delete(contractID, key string) error {
+	return s.ApplyVersion([]Change{{ContractID: contractID, Key: key, Deleted: true}}, "")
+}
+
+func (s *VersionedStore) Has(contractID, key string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.base.Has(contractID, key)
+}
+
+func (s *VersionedStore) Iterate(contractID, start, end string, fn func(key string, value []byte) bool) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.base.Iterate(contractID, start, end, fn)
+}
+
+// Export returns the current state of a contract
+func (s *VersionedStore) Export(contractID string) (map[string][]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return exportState(s.base, contractID)
+}
+
+// Apply applies the changes as a new, untagged version
+func (s *VersionedStore) Apply(changes []Change) error {
+	return s.ApplyVersion(changes, "")
+}
+
+// ApplyVersion applies the changes as a new version of every contract they
+// touch, tagged with blockID
+func (s *VersionedStore) ApplyVersion(changes []Change, blockID string) error {
+	for _, change := range changes {
+		if err := validateKey(change.ContractID, change.Key); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.applyVersion(changes, blockID)
+}
+
+// applyVersion is ApplyVersion for a caller holding s.mu
+func (s *VersionedStore) applyVersion(changes []Change, blockID string) error {
+	// Capture the values the changes overwrite before applying them
+	undo := make(map[string][]Change)
+	var order []string
+	for _, change := range changes {
+		previous := Change{ContractID: change.ContractID, Key: change.Key}
+		value, err := s.base.Get(change.ContractID, change.Key)
+		switch {
+		case errors.Is(err, ErrKeyNotFound):
+			previous.Deleted = true
+		case err != nil:
+			return err
+		default:
+			previous.Value = value
+		}
+
+		if _, ok := undo[change.ContractID]; !ok {
+			order = append(order, change.ContractID)
+		}
+		undo[change.ContractID] = append(undo[change.ContractID], previous)
+	}
+
+	now := time.Now()
+	records := make(map[string]*versionRecord, len(order))
+	for _, contractID := range order {
+		records[contractID] = &versionRecord{
+			Version: Version{Seq: s.contracts[contractID].current().Seq + 1, BlockID: blockID, Time: now},
+			undo:    undo[contractID],
+		}
+	}
+
+	// Log the versions ahead of the write, so that a crash in between
+	// leaves a version which changed nothing rather than an unrecorded
+	// change
+	unlog := func() error { return nil }
+	if s.log != nil {
+		var err error
+		if unlog, err = s.log.append(order, records); err != nil {
+			return err
+		}
+	}
+	if err := applyChanges(s.base, changes); err != nil {
+		return errors.Join(err, unlog())
+	}
+
+	for _, contractID := range order {
+		h := s.contracts[contractID]
+		if h == nil {
+			h = &history{}
+			s.contracts[contractID] = h
+		}
+		h.lastSeq = records[contractID].Seq
+		h.versions = append(h.versions, records[contractID])
+		s.prune(contractID, h, now)
+	}
+	return nil
+}
+
+// Versions returns the retained versions of a contract, oldest first
+func (s *VersionedStore) Versions(contractID string) []Version {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	h := s.contracts[contractID]
+	if h == nil {
+		return nil
+	}
+	versions := make([]Version, len(h.versions))
+	for i, record := range h.versions {
+		versions[i] = record.Version
+	}
+	return versions
+}
+
+// Latest returns the sequence number of the current version of a
+// contract, which is 0 if it has never changed
+func (s *VersionedStore) Latest(contractID string) uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if h := s.contracts[contractID]; h != nil {
+		return h.lastSeq
+	}
+	return 0
+}
+
+// StateAt returns the state of a contract as of version seq. The state
+// before the oldest retained version can still be read, as its changes
+// are known.
+func (s *VersionedStore) StateAt(contractID string, seq uint64) (map[string][]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.stateAt(contractID, seq)
+}
+
+// StateAtBlock returns the state of a contract as of the latest version
+// tagged with blockID
+func (s *VersionedStore) StateAtBlock(contractID, blockID string) (map[string][]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if h := s.contracts[contractID]; h != nil {
+		for i := len(h.versions) - 1; i >= 0; i-- {
+			if h.versions[i].BlockID == blockID {
+				return s.stateAt(contractID, h.versions[i].Seq)
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: contract %v has no version for block %v", ErrVersionNotFound, contractID, blockID)
+}
+
+// Restore sets the state of a contract back to version seq. The restore
+// is itself recorded as a new version, which is returned.
+func (s *VersionedStore) Restore(contractID string, seq uint64) (Version, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target, err := s.stateAt(contractID, seq)
+	if err != nil {
+		return Version{}, err
+	}
+	current, err := exportState(s.base, contractID)
+	if err != nil {
+		return Version{}, err
+	}
+
+	if changes := diffState(contractID, current, target); len(changes) > 0 {
+		if err := s.applyVersion(changes, ""); err != nil {
+			return Version{}, err
+		}
+	}
+
+	return s.contracts[contractID].current(), nil
+}
+
+// Prune drops the versions of every contract that the retention policy no
+// longer keeps, and returns how many were dropped. Versions are also
+// pruned whenever a new one is made.
+func (s *VersionedStore) Prune() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.pruneAll(time.Now())
+}
+
+// pruneAll prunes the history of every contract. The caller must hold
+// s.mu, or own s exclusively.
+func (s *VersionedStore) pruneAll(now time.Time) int {
+	pruned := 0
+	for contractID, h := range s.contracts {
+		pruned += s.prune(contractID, h, now)
+	}
+	return pruned
+}
+
+// prune drops the versions of a contract the retention policy no longer
+// keeps. If the history is persisted, the log is compacted once pruned
+// records make up most of it. A failed compaction leaves them in the log,
+// to be pruned again when it is loaded.
+func (s *VersionedStore) prune(contractID string, h *history, now time.Time) int {
+	drop := 0
+	if max := s.retention.MaxVersions; max > 0 && len(h.versions) > max {
+		drop = len(h.versions) - max
+	}
+	if maxAge := s.retention.MaxAge; maxAge > 0 {
+		for drop < len(h.versions) && now.Sub(h.versions[drop].Time) > maxAge {
+			drop++
+		}
+	}
+
+	// Clear the dropped records so their undo values can be collected
+	for i := 0; i < drop; i++ {
+		h.versions[i] = nil
+	}
+	h.versions = h.versions[drop:]
+
+	if s.log != nil && drop > 0 {
+		s.log.compact(contractID, h)
+	}
+	return drop
+}
+
+// stateAt reconstructs version seq by undoing every later version from the
+// current state. The caller must hold s.mu.
+func (s *VersionedStore) stateAt(contractID string, seq uint64) (map[string][]byte, error) {
+	h := s.contracts[contractID]
+	lastSeq, oldest := uint64(0), uint64(0)
+	if h != nil {
+		lastSeq = h.lastSeq
+		if len(h.versions) > 0 {
+			oldest = h.versions[0].Seq - 1
+		} else {
+			oldest = lastSeq
+		}
+	}
+	if seq > lastSeq || seq < oldest {
+		return nil, fmt.Errorf("%w: contract %v has no version %d", ErrVersionNotFound, contractID, seq)
+	}
+
+	entries, err := exportState(s.base, contractID)
+	if err != nil {
+		return nil, err
+	}
+	later := h.versionsAfter(seq)
+	for i := len(later) - 1; i >= 0; i-- {
+		record := later[i]
+		for j := len(record.undo) - 1; j >= 0; j-- {
+			change := record.undo[j]
+			if change.Deleted {
+				delete(entries, change.Key)
+			} else {
+				entries[change.Key] = append([]byte{}, change.Value...)
+			}
+		}
+	}
+	return entries, nil
+}
+
+// current returns the current version, even if it has been pruned
+func (h *history) current() Version {
+	if h == nil {
+		return Version{}
+	}
+	if n := len(h.versions); n > 0 && h.versions[n-1].Seq == h.lastSeq {
+		return h.versions[n-1].Version
+	}
+	return Version{Seq: h.lastSeq}
+}
+
+// versionsAfter returns the retained versions made after version seq
+func (h *history) versionsAfter(seq uint64) []*versionRecord {
+	if h == nil {
+		return nil
+	}
+	i := sort.Search(len(h.versions), func(i int) bool {
+		return h.versions[i].Seq > seq
+	})
+	return h.versions[i:]
+}
+
+// exportState reads the full state of a contract through Iterate
+func exportState(store StateStore, contractID string) (map[string][]byte, error) {
+	entries := make(map[string][]byte)
+	err := store.Iterate(contractID, "", "", func(key string, value []byte) bool {
+		entries[key] = value
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// diffState returns the changes that turn the state from into the state to
+func diffState(contractID string, from, to map[string][]byte) []Change {
+	var changes []Change
+	for _, key := range sortedKeys(from, "", "") {
+		if _, ok := to[key]; !ok {
+			changes = append(changes, Change{ContractID: contractID, Key: key, Deleted: true})
+		}
+	}
+	for _, key := range sortedKeys(to, "", "") {
+		if value, ok := from[key]; !ok || string(value) != string(to[key]) {
+			changes = append(changes, Change{ContractID: contractID, Key: key, Value: to[key]})
+		}
+	}
+	return changes
+}
+
+var _ Versioner = (*VersionedStore)(nil)
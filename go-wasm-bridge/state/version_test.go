@@ -0,0 +1,128 @@
+package state
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// setVersion commits one change to the count key of the contract counter
+// as a new version tagged with blockID
+func setVersion(t *testing.T, store *VersionedStore, count, blockID string) {
+	t.Helper()
+
+	change := Change{ContractID: "counter", Key: "count", Value: []byte(count)}
+	if err := store.ApplyVersion([]Change{change}, blockID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// expectCount checks the count key in a state read back from store
+func expectCount(t *testing.T, entries map[string][]byte, err error, count string) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(entries["count"]) != count {
+		t.Errorf("expected count %q, got %q", count, entries["count"])
+	}
+}
+
+func TestVersionedStore(t *testing.T) {
+	testStore(t, NewVersionedStore(NewMemoryStore(), RetentionPolicy{}))
+}
+
+func TestVersionedStoreHistory(t *testing.T) {
+	store := NewVersionedStore(NewMemoryStore(), RetentionPolicy{})
+	setVersion(t, store, "1", "block-1")
+	setVersion(t, store, "2", "block-2")
+	setVersion(t, store, "3", "")
+
+	if latest := store.Latest("counter"); latest != 3 {
+		t.Errorf("expected version 3 to be the latest, got %d", latest)
+	}
+	if latest := store.Latest("other"); latest != 0 {
+		t.Errorf("expected an unchanged contract to be at version 0, got %d", latest)
+	}
+
+	entries, err := store.StateAt("counter", 0)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("expected version 0 to be empty, got %v, %v", entries, err)
+	}
+	entries, err = store.StateAt("counter", 2)
+	expectCount(t, entries, err, "2")
+	entries, err = store.StateAtBlock("counter", "block-1")
+	expectCount(t, entries, err, "1")
+
+	if _, err := store.StateAt("counter", 4); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("expected ErrVersionNotFound for a future version, got %v", err)
+	}
+	if _, err := store.StateAtBlock("counter", "block-3"); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("expected ErrVersionNotFound for an unknown block, got %v", err)
+	}
+
+	// A restore is recorded as a new version
+	version, err := store.Restore("counter", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version.Seq != 4 {
+		t.Errorf("expected the restore to make version 4, got %d", version.Seq)
+	}
+	if value, err := store.Get("counter", "count"); err != nil || string(value) != "1" {
+		t.Errorf("expected count to be restored to 1, got %q, %v", value, err)
+	}
+	entries, err = store.StateAt("counter", 3)
+	expectCount(t, entries, err, "3")
+}
+
+func TestVersionedStoreRetention(t *testing.T) {
+	store := NewVersionedStore(NewMemoryStore(), RetentionPolicy{MaxVersions: 2})
+	for _, count := range []string{"1", "2", "3"} {
+		setVersion(t, store, count, "")
+	}
+
+	var seqs []uint64
+	for _, version := range store.Versions("counter") {
+		seqs = append(seqs, version.Seq)
+	}
+	if !reflect.DeepEqual(seqs, []uint64{2, 3}) {
+		t.Errorf("expected versions 2 and 3 to be retained, got %v", seqs)
+	}
+
+	// The state before the oldest retained version can still be read
+	entries, err := store.StateAt("counter", 1)
+	expectCount(t, entries, err, "1")
+	if _, err := store.StateAt("counter", 0); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("expected ErrVersionNotFound for a pruned version, got %v", err)
+	}
+}
+
+func TestVersionedStorePersists(t *testing.T) {
+	dir := t.TempDir()
+	base := NewMemoryStore()
+	store, err := OpenVersionedStore(base, RetentionPolicy{}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setVersion(t, store, "1", "block-1")
+	setVersion(t, store, "2", "block-2")
+
+	reopened, err := OpenVersionedStore(base, RetentionPolicy{}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions, persisted := store.Versions("counter"), reopened.Versions("counter")
+	if len(persisted) != len(versions) {
+		t.Fatalf("expected %d versions to persist, got %v", len(versions), persisted)
+	}
+	for i, version := range versions {
+		// The persisted times lose their monotonic clock reading
+		if persisted[i].Seq != version.Seq || persisted[i].BlockID != version.BlockID || !persisted[i].Time.Equal(version.Time) {
+			t.Errorf("expected version %+v to persist, got %+v", version, persisted[i])
+		}
+	}
+	entries, err := reopened.StateAtBlock("counter", "block-1")
+	expectCount(t, entries, err, "1")
+}
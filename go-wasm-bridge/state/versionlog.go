@@ -0,0 +1,211 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// versionLogSuffix is appended to the contract ID to name its version log
+const versionLogSuffix = ".versions.jsonl"
+
+// versionLog persists the version history of every contract as a JSON
+// lines file named <contract ID>.versions.jsonl, one record per version.
+// Records are only appended, except when pruning compacts the file.
+type versionLog struct {
+	dir string
+
+	// records holds the number of records in the log of each contract
+	records map[string]int
+}
+
+// logRecord is a line of a version log. A pruned record only carries the
+// sequence number of a version that is no longer retained, so that
+// numbering continues after every retained version has been pruned.
+type logRecord struct {
+	Version
+	Undo   []Change `json:"undo,omitempty"`
+	Pruned bool     `json:"pruned,omitempty"`
+}
+
+func newVersionLog(dir string) (*versionLog, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create version log directory: %w", err)
+	}
+	return &versionLog{dir: dir, records: make(map[string]int)}, nil
+}
+
+func (l *versionLog) path(contractID string) (string, error) {
+	if !contractIDPattern.MatchString(contractID) {
+		return "", fmt.Errorf("contract ID %q cannot be used as a version log name", contractID)
+	}
+	return filepath.Join(l.dir, contractID+versionLogSuffix), nil
+}
+
+// load reads the version history of every contract with a log in the
+// directory
+func (l *versionLog) load() (map[string]*history, error) {
+	files, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read version log directory: %w", err)
+	}
+
+	contracts := make(map[string]*history)
+	for _, file := range files {
+		contractID, ok := strings.CutSuffix(file.Name(), versionLogSuffix)
+		if !ok || file.IsDir() || !contractIDPattern.MatchString(contractID) {
+			continue
+		}
+		h, err := l.loadHistory(contractID)
+		if err != nil {
+			return nil, err
+		}
+		contracts[contractID] = h
+	}
+	return contracts, nil
+}
+
+// loadHistory reads the log of a contract. A torn last record, left by a
+// crash during an append, is cut off.
+func (l *versionLog) loadHistory(contractID string) (*history, error) {
+	logPath, err := l.path(contractID)
+	if err != nil {
+		return nil, err
+	}
+	logBytes, err := os.ReadFile(logPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read version log of contract %v: %w", contractID, err)
+	}
+
+	h := &history{}
+	offset := 0
+	for offset < len(logBytes) {
+		end := bytes.IndexByte(logBytes[offset:], '\n')
+		if end < 0 {
+			break
+		}
+
+		var record logRecord
+		if err := json.Unmarshal(logBytes[offset:offset+end], &record); err != nil {
+			return nil, fmt.Errorf("failed to parse version log %v: %w", logPath, err)
+		}
+		offset += end + 1
+		l.records[contractID]++
+
+		h.lastSeq = record.Seq
+		if !record.Pruned {
+			h.versions = append(h.versions, &versionRecord{Version: record.Version, undo: record.Undo})
+		}
+	}
+
+	if offset < len(logBytes) {
+		if err := os.Truncate(logPath, int64(offset)); err != nil {
+			return nil, fmt.Errorf("failed to repair version log %v: %w", logPath, err)
+		}
+	}
+	return h, nil
+}
+
+// append logs a new version of every contract in contractIDs. The
+// returned function removes them again.
+func (l *versionLog) append(contractIDs []string, records map[string]*versionRecord) (func() error, error) {
+	var undo []func() error
+	rollback := func() error {
+		var errs []error
+		for _, fn := range undo {
+			errs = append(errs, fn())
+		}
+		return errors.Join(errs...)
+	}
+
+	for _, contractID := range contractIDs {
+		fn, err := l.appendRecord(contractID, records[contractID])
+		if err != nil {
+			return nil, errors.Join(err, rollback())
+		}
+		undo = append(undo, fn)
+	}
+	return rollback, nil
+}
+
+func (l *versionLog) appendRecord(contractID string, record *versionRecord) (func() error, error) {
+	logPath, err := l.path(contractID)
+	if err != nil {
+		return nil, err
+	}
+	line, err := json.Marshal(logRecord{Version: record.Version, Undo: record.undo})
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open version log of contract %v: %w", contractID, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open version log of contract %v: %w", contractID, err)
+	}
+	size := info.Size()
+	truncate := func() error {
+		l.records[contractID]--
+		return os.Truncate(logPath, size)
+	}
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		os.Truncate(logPath, size)
+		return nil, fmt.Errorf("failed to write version log of contract %v: %w", contractID, err)
+	}
+	l.records[contractID]++
+	return truncate, nil
+}
+
+// compact rewrites the log of a contract with its retained versions once
+// pruned records make up more than half of it
+func (l *versionLog) compact(contractID string, h *history) error {
+	if l.records[contractID] <= 2*len(h.versions)+1 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	records := h.versions
+	if len(records) == 0 {
+		records = []*versionRecord{{Version: h.current()}}
+	}
+	for _, record := range records {
+		line := logRecord{Version: record.Version, Undo: record.undo, Pruned: len(h.versions) == 0}
+		if err := encoder.Encode(line); err != nil {
+			return err
+		}
+	}
+
+	logPath, err := l.path(contractID)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(l.dir, contractID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to compact version log of contract %v: %w", contractID, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to compact version log of contract %v: %w", contractID, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to compact version log of contract %v: %w", contractID, err)
+	}
+	if err := os.Rename(tmp.Name(), logPath); err != nil {
+		return err
+	}
+	l.records[contractID] = len(records)
+	return nil
+}
@@ -0,0 +1,176 @@
+package wasmbridge
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bytecodealliance/wasmtime-go"
+	wasmContext "github.com/rubixchain/rubix-wasm/go-wasm-bridge/context"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host"
+	hostState "github.com/rubixchain/rubix-wasm/go-wasm-bridge/host/state"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/state"
+)
+
+// stateFunctions are the state host functions called by stateContract
+var stateFunctions = []string{"state_get", "state_set", "state_delete", "state_has", "state_iter_prefix", "state_range"}
+
+// stateContract exports <name>_ for every state host function, which
+// passes its input to the host function
+var stateContract = func() []byte {
+	imports, funcs := "", []string{failFunc, trapFunc}
+	for _, name := range stateFunctions {
+		imports += hostImport("env", name) + "\n  "
+		funcs = append(funcs, hostCallFunc(name))
+	}
+	return testContract(imports, funcs...)
+}()
+
+// responseRegistry returns a registry which records the response of every
+// successful host function call in responses
+func responseRegistry(responses *[]string) *HostFunctionRegistry {
+	registry := NewHostFunctionRegistry()
+	registry.Use(func(next host.Handler) host.Handler {
+		return func(inv *host.Invocation) ([]wasmtime.Val, *wasmtime.Trap) {
+			results, trap := next(inv)
+			if trap == nil {
+				*responses = append(*responses, string(inv.Output()))
+			}
+			return results, trap
+		}
+	})
+	return registry
+}
+
+// newStateModule loads stateContract on store, recording host function
+// responses in responses
+func newStateModule(t *testing.T, store state.StateStore, responses *[]string, opts ...WasmModuleOption) *WasmModule {
+	t.Helper()
+
+	opts = append([]WasmModuleOption{WithStateStore(store), WithContractID("counter")}, opts...)
+	module, err := NewWasmModuleFromBytes(stateContract, responseRegistry(responses), opts...)
+	if err != nil {
+		t.Fatalf("failed to load state contract: %v", err)
+	}
+	return module
+}
+
+// lastResponse returns the latest recorded host function response
+func lastResponse(t *testing.T, responses []string) string {
+	t.Helper()
+
+	if len(responses) == 0 {
+		t.Fatal("no host function was called")
+	}
+	return responses[len(responses)-1]
+}
+
+func TestStateHostFunctions(t *testing.T) {
+	store := state.NewMemoryStore()
+	var responses []string
+	module := newStateModule(t, store, &responses)
+
+	if _, err := module.Call("state_set", []byte(`{"key": "count", "value": "1"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := store.Get("counter", "count"); err != nil || string(value) != "1" {
+		t.Fatalf("expected count to be stored as 1, got %q, %v", value, err)
+	}
+
+	if _, err := module.Call("state_get", []byte(`{"key": "count"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if response := lastResponse(t, responses); response != "1" {
+		t.Errorf("expected state_get to respond with the raw value, got %q", response)
+	}
+
+	if _, err := module.Call("state_has", []byte(`{"key": "count"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if response := lastResponse(t, responses); response != "true" {
+		t.Errorf("expected state_has to respond true, got %q", response)
+	}
+
+	if _, err := module.Call("state_delete", []byte(`{"key": "count"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("counter", "count"); !errors.Is(err, state.ErrKeyNotFound) {
+		t.Fatalf("expected count to be deleted, got %v", err)
+	}
+
+	if _, err := module.Call("state_has", []byte(`{"key": "count"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if response := lastResponse(t, responses); response != "false" {
+		t.Errorf("expected state_has to respond false, got %q", response)
+	}
+}
+
+func TestStateGetMissingKey(t *testing.T) {
+	var responses []string
+	module := newStateModule(t, state.NewMemoryStore(), &responses)
+
+	_, err := module.Call("state_get", []byte(`{"key": "missing"}`))
+	var contractErr *ContractError
+	if !errors.As(err, &contractErr) || contractErr.Code != hostState.CodeKeyNotFound {
+		t.Fatalf("expected a ContractError with code %d, got %v", hostState.CodeKeyNotFound, err)
+	}
+	if response := lastResponse(t, responses); response != `{"code":404,"message":"state key \"missing\" not found"}` {
+		t.Errorf("unexpected error payload %s", response)
+	}
+}
+
+func TestStateKeysArePerContract(t *testing.T) {
+	store := state.NewMemoryStore()
+	var responses []string
+	first := newStateModule(t, store, &responses, WithContractID("first"))
+	second := newStateModule(t, store, &responses, WithContractID("second"))
+
+	if _, err := first.Call("state_set", []byte(`{"key": "owner", "value": "alice"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.Call("state_has", []byte(`{"key": "owner"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if response := lastResponse(t, responses); response != "false" {
+		t.Errorf("expected the key of another contract to be invisible, got %q", response)
+	}
+}
+
+func TestStateWithoutStore(t *testing.T) {
+	module := newTestModule(t, stateContract)
+
+	var hostErr *HostFunctionError
+	if _, err := module.Call("state_get", []byte(`{"key": "count"}`)); !errors.As(err, &hostErr) {
+		t.Fatalf("expected a HostFunctionError, got %v", err)
+	}
+}
+
+func TestStateModulesSharingWasmContext(t *testing.T) {
+	wasmCtx := wasmContext.NewWasmContext()
+	firstStore, secondStore := state.NewMemoryStore(), state.NewMemoryStore()
+	var responses []string
+	first := newStateModule(t, firstStore, &responses, WithWasmContext(wasmCtx), WithContractID("first"))
+	second := newStateModule(t, secondStore, &responses, WithWasmContext(wasmCtx), WithContractID("second"))
+
+	// Each module writes to its own store under its own contract ID
+	if _, err := first.Call("state_set", []byte(`{"key": "owner", "value": "alice"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.Call("state_set", []byte(`{"key": "owner", "value": "bob"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := firstStore.Get("first", "owner"); err != nil || string(value) != "alice" {
+		t.Errorf("expected alice in the first store, got %q, %v", value, err)
+	}
+	if value, err := secondStore.Get("second", "owner"); err != nil || string(value) != "bob" {
+		t.Errorf("expected bob in the second store, got %q, %v", value, err)
+	}
+	if ok, _ := secondStore.Has("first", "owner"); ok {
+		t.Error("expected the first module not to write to the second store")
+	}
+
+	// The shared context itself is left untouched
+	if wasmCtx.ContractID() != "" || wasmCtx.StateStore() != nil {
+		t.Errorf("expected the shared context to be unmodified, got contract ID %q", wasmCtx.ContractID())
+	}
+}
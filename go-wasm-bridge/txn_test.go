@@ -0,0 +1,99 @@
+package wasmbridge
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/state"
+)
+
+// setThenFunc returns the contract function <export>_, which passes its
+// input to state_set and then runs tail
+func setThenFunc(export, tail string) string {
+	return fmt.Sprintf(`(func (export "%s_") (param i32 i32 i32 i32) (result i32)
+    local.get 0
+    local.get 1
+    local.get 2
+    local.get 3
+    call $state_set
+    drop
+    local.get 2
+    i32.const 16
+    i32.store
+    local.get 3
+    i32.const 4
+    i32.store
+    %s)`, export, tail)
+}
+
+// txnContract writes state and then succeeds, fails or traps
+var txnContract = testContract(hostImport("env", "state_set"),
+	setThenFunc("set_ok", "i32.const 0"),
+	setThenFunc("set_fail", "i32.const 1"),
+	setThenFunc("set_trap", "unreachable"),
+)
+
+func TestStateCommittedOnSuccess(t *testing.T) {
+	store := state.NewMemoryStore()
+	module := newTestModule(t, txnContract, WithStateStore(store), WithContractID("counter"))
+
+	result, err := module.Call("set_ok", []byte(`{"key": "count", "value": "1"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := store.Get("counter", "count"); err != nil || string(value) != "1" {
+		t.Fatalf("expected count to be committed, got %q, %v", value, err)
+	}
+
+	expected := state.Change{ContractID: "counter", Key: "count", Value: []byte("1")}
+	if len(result.StateChanges) != 1 || fmt.Sprint(result.StateChanges[0]) != fmt.Sprint(expected) {
+		t.Errorf("expected state changes [%+v], got %+v", expected, result.StateChanges)
+	}
+}
+
+func TestStateRolledBack(t *testing.T) {
+	tests := []struct {
+		name     string
+		function string
+		check    func(error) bool
+	}{
+		{"return code", "set_fail", func(err error) bool {
+			var contractErr *ContractError
+			return errors.As(err, &contractErr) && contractErr.Code == 1
+		}},
+		{"trap", "set_trap", func(err error) bool {
+			var trapErr *TrapError
+			return errors.As(err, &trapErr)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := state.NewMemoryStore()
+			if err := store.Set("counter", "count", []byte("0")); err != nil {
+				t.Fatal(err)
+			}
+			module := newTestModule(t, txnContract, WithStateStore(store), WithContractID("counter"))
+
+			result, err := module.Call(tt.function, []byte(`{"key": "count", "value": "1"}`))
+			if !tt.check(err) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if value, err := store.Get("counter", "count"); err != nil || string(value) != "0" {
+				t.Errorf("expected the write to be rolled back, got %q, %v", value, err)
+			}
+			if result != nil && len(result.StateChanges) != 0 {
+				t.Errorf("expected no state changes, got %+v", result.StateChanges)
+			}
+
+			// The next call does not see the discarded write either
+			if _, err := module.Call("set_ok", []byte(`{"key": "other", "value": "2"}`)); err != nil {
+				t.Fatal(err)
+			}
+			if value, err := store.Get("counter", "count"); err != nil || string(value) != "0" {
+				t.Errorf("expected the discarded write to stay discarded, got %q, %v", value, err)
+			}
+		})
+	}
+}
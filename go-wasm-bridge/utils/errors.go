@@ -1,6 +1,11 @@
 package utils
 
-import "github.com/bytecodealliance/wasmtime-go"
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/bytecodealliance/wasmtime-go"
+)
 
 // Error handle functions
 
@@ -11,3 +16,27 @@ func HandleError(errMsg string) ([]wasmtime.Val, *wasmtime.Trap) {
 func HandleOk() ([]wasmtime.Val, *wasmtime.Trap) {
 	return []wasmtime.Val{wasmtime.ValI32(0)}, nil
 }
+
+// HandleGuestError writes the JSON error payload {"code", "message"} to
+// the response pointers of the contract and returns code, so that the
+// contract can handle the failure. A trap is only returned if the payload
+// cannot be written.
+func HandleGuestError(caller *wasmtime.Caller, allocFunction *wasmtime.Func, outputArg *WasmArgInfo, code int32, errMsg string) ([]wasmtime.Val, *wasmtime.Trap) {
+	if code == 0 {
+		code = 1
+	}
+
+	payload, err := json.Marshal(struct {
+		Code    int32  `json:"code"`
+		Message string `json:"message"`
+	}{code, errMsg})
+	if err != nil {
+		return HandleError(err.Error())
+	}
+
+	if err := UpdateDataToWASM(caller, allocFunction, string(payload), outputArg); err != nil {
+		return HandleError(fmt.Sprintf("failed to return error %q to the contract: %v", errMsg, err))
+	}
+
+	return []wasmtime.Val{wasmtime.ValI32(code)}, nil
+}
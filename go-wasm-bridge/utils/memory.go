@@ -13,12 +13,14 @@ type WasmArgInfo struct {
 	DataPtrSize int32
 }
 
-func HostFunctionParamExtraction(args []wasmtime.Val, areInputArgsPresent bool, areOutputArgsPresent bool) (*WasmArgInfo, *WasmArgInfo) {
+func HostFunctionParamExtraction(args []wasmtime.Val, areInputArgsPresent bool, areOutputArgsPresent bool) (*WasmArgInfo, *WasmArgInfo, error) {
 	var nArgs int = len(args)
 
 	if ((nArgs % 2) != 0) || (nArgs == 0) || (nArgs > 4) {
-		fmt.Printf("Invalied number of Arguments")
-		return nil, nil
+		return nil, nil, fmt.Errorf("invalid number of arguments: %d", nArgs)
+	}
+	if areInputArgsPresent && areOutputArgsPresent && nArgs != 4 {
+		return nil, nil, fmt.Errorf("invalid number of arguments: expected 4, got %d", nArgs)
 	}
 
 	inputArg := &WasmArgInfo{}
@@ -41,7 +43,7 @@ func HostFunctionParamExtraction(args []wasmtime.Val, areInputArgsPresent bool,
 		}
 	}
 
-	return inputArg, outputArg
+	return inputArg, outputArg, nil
 }
 
 func ExtractDataFromWASM(caller *wasmtime.Caller, inputArg *WasmArgInfo) ([]byte, *wasmtime.Memory, error) {
@@ -63,10 +65,9 @@ func ExtractDataFromWASM(caller *wasmtime.Caller, inputArg *WasmArgInfo) ([]byte
 	inputStart := int(inputArg.DataPtr)
 	inputEnd := inputStart + int(inputArg.DataPtrSize)
 
-	// Validate memory bounds
-	if inputStart < 0 || inputEnd > len(wasmMemory) {
-		errMsg := "input exceeds memory bounds"
-		return nil, nil, errors.New(errMsg)
+	// Validate memory bounds, the pointer and length come from the guest
+	if inputStart < 0 || inputArg.DataPtrSize < 0 || inputEnd < inputStart || inputEnd > len(wasmMemory) {
+		return nil, nil, fmt.Errorf("input of %d bytes at %d exceeds memory bounds", inputArg.DataPtrSize, inputArg.DataPtr)
 	}
 
 	// Extract input bytes and convert to string
@@ -103,7 +104,7 @@ func UpdateDataToWASM(caller *wasmtime.Caller, allocFunction *wasmtime.Func, out
 
 	// Get memory size to ensure we don't write out of bounds
 	memSize := memory.DataSize(caller)
-	if uint32(respPtr)+uint32(outputValueLen) > uint32(memSize) {
+	if respPtr < 0 || uint64(respPtr)+uint64(outputValueLen) > uint64(memSize) {
 		errMsg := "Response exceeds memory bounds"
 		return fmt.Errorf("%s", errMsg)
 	}
@@ -114,6 +115,12 @@ func UpdateDataToWASM(caller *wasmtime.Caller, allocFunction *wasmtime.Func, out
 	respPtrPtr := outputArg.DataPtr
 	respLenPtr := outputArg.DataPtrSize
 
+	// Validate that the response pointer and length can be written back
+	if respPtrPtr < 0 || int(respPtrPtr)+4 > len(wasmMemory) || respLenPtr < 0 || int(respLenPtr)+4 > len(wasmMemory) {
+		errMsg := "Response pointers exceed memory bounds"
+		return fmt.Errorf("%s", errMsg)
+	}
+
 	// Write the response pointer back to WASM memory using Little Endian encoding
 	binary.LittleEndian.PutUint32(wasmMemory[respPtrPtr:], uint32(respPtr))
 
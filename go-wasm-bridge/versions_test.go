@@ -0,0 +1,140 @@
+package wasmbridge
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/state"
+)
+
+// openVersionedModule loads txnContract on a FileStore in dir, with the
+// version history persisted next to it
+func openVersionedModule(t *testing.T, dir string, retention state.RetentionPolicy) *WasmModule {
+	t.Helper()
+
+	files, err := state.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := state.OpenVersionedStore(files, retention, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newTestModule(t, txnContract, WithStateStore(store), WithContractID("counter"))
+}
+
+// setCount sets the key count through the contract
+func setCount(t *testing.T, module *WasmModule, count int, callOpts ...CallOption) {
+	t.Helper()
+
+	input := fmt.Sprintf(`{"key": "count", "value": "%d"}`, count)
+	if _, err := module.Call("set_ok", []byte(input), callOpts...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// expectSeqs checks the sequence numbers of the retained versions
+func expectSeqs(t *testing.T, module *WasmModule, expected ...uint64) {
+	t.Helper()
+
+	versions, err := module.StateVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	seqs := make([]uint64, len(versions))
+	for i, version := range versions {
+		seqs[i] = version.Seq
+	}
+	if fmt.Sprint(seqs) != fmt.Sprint(expected) {
+		t.Errorf("expected versions %v, got %v", expected, seqs)
+	}
+}
+
+func TestStateVersionsCountCommits(t *testing.T) {
+	module := openVersionedModule(t, t.TempDir(), state.RetentionPolicy{})
+
+	setCount(t, module, 1)
+	// Failed calls commit nothing and make no version
+	if _, err := module.Call("set_fail", []byte(`{"key": "count", "value": "9"}`)); err == nil {
+		t.Fatal("expected set_fail to fail")
+	}
+	setCount(t, module, 2)
+
+	expectSeqs(t, module, 1, 2)
+}
+
+func TestStateVersionsPersist(t *testing.T) {
+	dir := t.TempDir()
+	module := openVersionedModule(t, dir, state.RetentionPolicy{})
+	setCount(t, module, 1, WithBlockID("block-1"))
+	setCount(t, module, 2, WithBlockID("block-2"))
+
+	// The history is read back by a store opened on the same directory
+	reopened := openVersionedModule(t, dir, state.RetentionPolicy{})
+	expectSeqs(t, reopened, 1, 2)
+
+	entries, err := reopened.StateAtBlock("block-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(entries["count"]) != "1" {
+		t.Errorf("expected count 1 at block-1, got %q", entries["count"])
+	}
+
+	version, err := reopened.RestoreState(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version.Seq != 3 {
+		t.Errorf("expected the restore to make version 3, got %d", version.Seq)
+	}
+	setCount(t, reopened, 5)
+	expectSeqs(t, openVersionedModule(t, dir, state.RetentionPolicy{}), 1, 2, 3, 4)
+}
+
+func TestStateVersionLogCompaction(t *testing.T) {
+	dir := t.TempDir()
+	retention := state.RetentionPolicy{MaxVersions: 2}
+	module := openVersionedModule(t, dir, retention)
+	for i := 1; i <= 10; i++ {
+		setCount(t, module, i)
+	}
+
+	logBytes, err := os.ReadFile(filepath.Join(dir, "counter.versions.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records := bytes.Count(logBytes, []byte("\n")); records > 5 {
+		t.Errorf("expected the log to be compacted to at most 5 records, got %d", records)
+	}
+
+	reopened := openVersionedModule(t, dir, retention)
+	expectSeqs(t, reopened, 9, 10)
+	setCount(t, reopened, 11)
+	expectSeqs(t, reopened, 10, 11)
+}
+
+func TestStateVersionLogTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	module := openVersionedModule(t, dir, state.RetentionPolicy{})
+	setCount(t, module, 1)
+
+	// A crash during an append leaves a partial record behind
+	logPath := filepath.Join(dir, "counter.versions.jsonl")
+	file, err := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString(`{"seq": 2, "ti`); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	reopened := openVersionedModule(t, dir, state.RetentionPolicy{})
+	expectSeqs(t, reopened, 1)
+	setCount(t, reopened, 2)
+	expectSeqs(t, openVersionedModule(t, dir, state.RetentionPolicy{}), 1, 2)
+}
@@ -3,23 +3,27 @@
 package wasmbridge
 
 import (
-	"bytes"
+	"context"
 	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
+	"time"
 
 	"github.com/bytecodealliance/wasmtime-go"
 	wasmContext "github.com/rubixchain/rubix-wasm/go-wasm-bridge/context"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/rubixclient"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/signer"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/state"
 )
 
 // WasmModule encapsulates the WASM module and its associated functions.
 type WasmModule struct {
 	// Wasmtime Runtime elements
 	engine      *wasmtime.Engine
+	module      *wasmtime.Module
 	store       *wasmtime.Store
 	instance    *wasmtime.Instance
 	memory      *wasmtime.Memory
@@ -29,105 +33,215 @@ type WasmModule struct {
 	// Rubix Blockchain elements
 	nodeAddress string
 	quorumType  int
+	rubixClient *rubixclient.Client
+	signer      signer.Signer
+
+	// Gas metering
+	gasLimit uint64
+	gasUsed  uint64
+
+	// trapped is set once guest code has trapped, after which the
+	// instance's memory can no longer be trusted
+	trapped bool
+
+	// Host functions the module is instantiated with
+	registry *HostFunctionRegistry
+
+	// Host functions invoked during the current call
+	hostCalls []string
+	hostErr   *HostFunctionError
 
 	// Context
 	wasmCtx *wasmContext.WasmContext
-}
 
-type SmartContractDataReply struct {
-	BasicResponse
-	SCTDataReply []SCTDataReply
-}
+	// Compilation
+	moduleCache *ModuleCache
+	limits      resourceLimits
 
-type BasicResponse struct {
-	Status  bool        `json:"status"`
-	Message string      `json:"message"`
-	Result  interface{} `json:"result"`
-}
+	// Contract functions exported by the module
+	functions []string
+
+	// Capabilities granted to the contract, nil if unrestricted
+	capabilities []string
+
+	// Hand host function errors back to the contract instead of trapping
+	guestErrors bool
 
-type SCTDataReply struct {
-	BlockNo           uint64
-	BlockId           string
-	SmartContractData string
+	// Contract state
+	contractID string
+	stateStore state.StateStore
+	root       *cachedRoot
 }
 
+type SmartContractDataReply = rubixclient.SmartContractDataReply
+
+type BasicResponse = rubixclient.BasicResponse
+
+type SCTDataReply = rubixclient.SCTDataReply
+
 // WasmModuleOption allows us to configure WasmModule
 type WasmModuleOption func(*WasmModule)
 
-// NewWasmModule initializes and returns a new WasmModule.
-
+// NewWasmModule initializes and returns a new WasmModule from the WASM
+// binary or WAT text at wasmFilePath.
 func NewWasmModule(wasmFilePath string, registry *HostFunctionRegistry, wasmModuleOpts ...WasmModuleOption) (*WasmModule, error) {
+	// Read the WASM file
+	wasmBytes, err := os.ReadFile(wasmFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWasmModuleFromBytes(wasmBytes, registry, wasmModuleOpts...)
+}
+
+// newWasmModule returns a WasmModule with default params and the
+// provided configurations applied
+func newWasmModule(registry *HostFunctionRegistry, wasmModuleOpts ...WasmModuleOption) *WasmModule {
 	// Define Wasm Module with default params
 	wasmModule := &WasmModule{
+		registry:    registry,
 		nodeAddress: "http://localhost:20006",
 		quorumType:  2,
 	}
 
-	// Read the WASM file
-	wasmBytes, err := os.ReadFile(wasmFilePath)
-	if err != nil {
-		return nil, err
+	// Apply Wasm Configurations
+	for _, opt := range wasmModuleOpts {
+		opt(wasmModule)
+	}
+	// Every module gets its own copy of the WasmContext, as it is bound to
+	// the contract ID, state and records of the module. The context passed
+	// to WithWasmContext is never modified.
+	if wasmModule.wasmCtx == nil {
+		wasmModule.wasmCtx = wasmContext.NewWasmContext()
+	} else {
+		wasmModule.wasmCtx = wasmModule.wasmCtx.Clone()
+	}
+	if wasmModule.guestErrors {
+		wasmModule.wasmCtx.WithGuestErrors(true)
+	}
+	if wasmModule.rubixClient != nil {
+		wasmModule.wasmCtx.WithRubixClient(wasmModule.rubixClient)
+	}
+	if wasmModule.signer != nil {
+		wasmModule.wasmCtx.WithSigner(wasmModule.signer)
 	}
 
-	wasmModule.engine = wasmtime.NewEngine()
-	wasmModule.store = wasmtime.NewStore(wasmModule.engine)
-	linker := wasmtime.NewLinker(wasmModule.engine)
+	return wasmModule
+}
 
-	for _, hf := range registry.GetHostFunctions() {
-		err := linker.Define("env", hf.Name(), wasmtime.NewFunc(
-			wasmModule.store,
-			hf.FuncType(),
-			hf.Callback(),
-		))
-		if err != nil {
-			return nil, fmt.Errorf("failed to define host function %s: %w", hf.Name(), err)
-		}
+// compile compiles the WASM binary or WAT text, going through the module
+// cache if one is configured, and sets the engine of the WasmModule.
+// Contracts which do not fit in the memory and table limits are rejected.
+func (w *WasmModule) compile(src []byte) error {
+	wasmBytes, err := toWasmBinary(src)
+	if err != nil {
+		return err
+	}
+	if w.contractID == "" {
+		w.contractID = contentContractID(wasmBytes)
 	}
 
-	module, err := wasmtime.NewModule(wasmModule.engine, wasmBytes)
+	if w.moduleCache != nil {
+		w.engine = w.moduleCache.engine
+		w.module, err = w.moduleCache.Module(wasmBytes)
+	} else {
+		w.engine = newEngine()
+		w.module, err = wasmtime.NewModule(w.engine, wasmBytes)
+	}
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	wasmModule.instance, err = linker.Instantiate(wasmModule.store, module)
+	return w.limits.check(wasmBytes, w.module)
+}
+
+// instantiate instantiates the compiled module in a new store, replacing
+// the previous instance if there is one
+func (w *WasmModule) instantiate() error {
+	functions, err := validateABI(w.module)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	w.functions = functions
 
-	wasmModule.memory = wasmModule.instance.GetExport(wasmModule.store, "memory").Memory()
-	if wasmModule.memory == nil {
-		return nil, errors.New("failed to find memory export")
+	if report := checkImports(w.module, w.registry); !report.OK() {
+		return &UnresolvedImportsError{Report: report}
 	}
 
-	wasmModule.allocFunc = wasmModule.instance.GetExport(wasmModule.store, "alloc").Func()
-	if wasmModule.allocFunc == nil {
-		return nil, errors.New("failed to find alloc function")
+	w.wasmCtx.WithContractID(w.contractID)
+	if w.stateStore != nil {
+		w.wasmCtx.WithStateStore(w.stateStore)
 	}
 
-	wasmModule.deallocFunc = wasmModule.instance.GetExport(wasmModule.store, "dealloc").Func()
-	if wasmModule.deallocFunc == nil {
-		return nil, errors.New("failed to find dealloc function")
+	w.store = wasmtime.NewStore(w.engine)
+
+	// Instantiation may run guest code, so it is not metered
+	if err := w.setFuel(0); err != nil {
+		return fmt.Errorf("failed to add fuel to the store: %w", err)
 	}
+	w.store.SetEpochDeadline(noEpochDeadline)
 
-	// Apply Wasm Configurations
-	for _, opt := range wasmModuleOpts {
-		opt(wasmModule)
+	linker := wasmtime.NewLinker(w.engine)
+
+	// Every module gets its own host function instances
+	hostFunctions := w.registry.newInstances()
+	middlewares := w.registry.getMiddlewares()
+	for _, hf := range hostFunctions {
+		callback := hf.Callback()
+		if !w.allowed(hf) {
+			callback = w.deniedHostCall(hf)
+		}
+		callback = host.Chain(host.Invocation{
+			Namespace: host.NamespaceOf(hf),
+			Name:      hf.Name(),
+			WasmCtx:   w.wasmCtx,
+		}, callback, middlewares)
+
+		err := linker.Define(host.NamespaceOf(hf), hf.Name(), wasmtime.NewFunc(
+			w.store,
+			hf.FuncType(),
+			w.recordHostCall(qualifiedName(hf), callback),
+		))
+		if err != nil {
+			return fmt.Errorf("failed to define host function %s: %w", qualifiedName(hf), err)
+		}
+	}
+
+	w.instance, err = linker.Instantiate(w.store, w.module)
+	if err != nil {
+		return err
+	}
+
+	w.memory = w.instance.GetExport(w.store, "memory").Memory()
+	if w.memory == nil {
+		return errors.New("failed to find memory export")
+	}
+
+	w.allocFunc = w.instance.GetExport(w.store, "alloc").Func()
+	if w.allocFunc == nil {
+		return errors.New("failed to find alloc function")
+	}
+
+	w.deallocFunc = w.instance.GetExport(w.store, "dealloc").Func()
+	if w.deallocFunc == nil {
+		return errors.New("failed to find dealloc function")
 	}
 
 	// Initialize all host functions with allocFunc, deallocFunc, and memory
-	for _, hf := range registry.GetHostFunctions() {
+	for _, hf := range hostFunctions {
 		hf.Initialize(
-			wasmModule.allocFunc,
-			wasmModule.deallocFunc,
-			wasmModule.memory,
-			wasmModule.nodeAddress,
-			wasmModule.quorumType,
-			wasmModule.wasmCtx,
+			w.allocFunc,
+			w.deallocFunc,
+			w.memory,
+			w.nodeAddress,
+			w.quorumType,
+			w.wasmCtx,
 		)
 
 	}
 
-	return wasmModule, nil
+	w.trapped = false
+	return nil
 }
 
 func WithRubixNodeAddress(nodeAddress string) WasmModuleOption {
@@ -136,18 +250,47 @@ func WithRubixNodeAddress(nodeAddress string) WasmModuleOption {
 	}
 }
 
+// WithRubixClient makes host functions call the Rubix node through client,
+// e.g. to configure its timeout. Its node address takes precedence over
+// WithRubixNodeAddress for host functions.
+func WithRubixClient(client *rubixclient.Client) WasmModuleOption {
+	return func(w *WasmModule) {
+		w.rubixClient = client
+	}
+}
+
+// WithSigner answers the signature requests of the token operations the
+// contract starts with s. Without a signer, such operations fail with
+// signer.ErrNoSigner.
+func WithSigner(s signer.Signer) WasmModuleOption {
+	return func(w *WasmModule) {
+		w.signer = s
+	}
+}
+
 func WithQuorumType(quorumType int) WasmModuleOption {
 	return func(w *WasmModule) {
 		w.quorumType = quorumType
 	}
 }
 
+// WithWasmContext sets the WasmContext passed to the host functions. The
+// module works on a copy of it.
 func WithWasmContext(wasmCtx *wasmContext.WasmContext) WasmModuleOption {
 	return func(w *WasmModule) {
 		w.wasmCtx = wasmCtx
 	}
 }
 
+// WithGuestErrors makes host functions hand their errors back to the
+// contract as a {"code", "message"} payload with a non-zero return code,
+// instead of trapping. Traps remain for ABI violations.
+func WithGuestErrors() WasmModuleOption {
+	return func(w *WasmModule) {
+		w.guestErrors = true
+	}
+}
+
 func (w *WasmModule) GetNodeAddress() string {
 	return w.nodeAddress
 }
@@ -165,10 +308,14 @@ func (w *WasmModule) allocate(data []byte) (int32, error) {
 	size := len(data)
 	result, err := w.allocFunc.Call(w.store, size)
 	if err != nil {
+		w.trapped = true
 		return 0, err
 	}
 	ptr := result.(int32)
 	memoryData := w.memory.UnsafeData(w.store)
+	if ptr < 0 || int(ptr)+size > len(memoryData) {
+		return 0, fmt.Errorf("alloc returned out of bounds pointer %d for %d bytes", ptr, size)
+	}
 	copy(memoryData[ptr:ptr+int32(size)], data)
 	return ptr, nil
 }
@@ -176,94 +323,223 @@ func (w *WasmModule) allocate(data []byte) (int32, error) {
 // deallocate frees memory in WASM.
 func (w *WasmModule) deallocate(ptr int32, size int32) error {
 	_, err := w.deallocFunc.Call(w.store, ptr, size)
+	if err != nil {
+		w.trapped = true
+	}
 	return err
 }
 
+// outOfGas reports whether a call which started at startFuel has exhausted
+// its gas limit
+func (w *WasmModule) outOfGas(cfg *callConfig, startFuel uint64) bool {
+	return cfg.gasLimit != 0 && fuelConsumed(w.store)-startFuel >= cfg.gasLimit
+}
+
+// guestError classifies an error returned while running guest code
+func (w *WasmModule) guestError(ctx context.Context, funcName string, err error, cfg *callConfig, startFuel uint64) error {
+	w.trapped = true
+
+	switch {
+	case w.outOfGas(cfg, startFuel):
+		return newTrapError(funcName, fmt.Errorf("gas limit of %d exceeded", cfg.gasLimit), TrapOutOfGas)
+	case ctx.Err() != nil:
+		trapErr := newTrapError(funcName, ctx.Err(), TrapInterrupted)
+		trapErr.Err = ctx.Err()
+		return trapErr
+	case w.hostErr != nil:
+		return w.hostErr
+	case isInterrupt(err):
+		trapErr := newTrapError(funcName, err, TrapInterrupted)
+		trapErr.Err = ErrSharedInterrupt
+		return trapErr
+	}
+
+	trapErr := newTrapError(funcName, err, TrapUnknown)
+	if (trapErr.Kind == TrapUnknown || trapErr.Kind == TrapUnreachable) && w.atMemoryLimit() {
+		// Contracts abort when memory.grow is denied
+		trapErr.Kind = TrapResourceLimit
+		trapErr.Message = fmt.Sprintf("memory limit of %d pages reached: %v", w.memory.Size(w.store), trapErr.Message)
+	}
+	return trapErr
+}
+
 // CallFunctions invokes the exported WASM function and returns the
-// result in string format
-func (w *WasmModule) CallFunction(args string) (string, error) {
+// result in string format. The gas consumed by the call is available
+// through GasUsed
+func (w *WasmModule) CallFunction(args string, callOpts ...CallOption) (string, error) {
+	return w.CallFunctionContext(context.Background(), args, callOpts...)
+}
+
+// CallFunctionContext is like CallFunction, but interrupts the running
+// contract once ctx is cancelled or its deadline passes. The same ctx is
+// handed to host functions through the module's WasmContext, so that
+// in-flight node requests are cancelled as well. A contract which was
+// interrupted or trapped is instantiated anew before its next call.
+func (w *WasmModule) CallFunctionContext(ctx context.Context, args string, callOpts ...CallOption) (string, error) {
+	result, err := w.Execute(ctx, args, callOpts...)
+	if err != nil {
+		return "", err
+	}
+	return result.Output, nil
+}
+
+// Execute invokes the exported WASM function and returns a CallResult
+// describing the call. args is a JSON object with exactly one key, the
+// function name, holding the function input. Failures are reported as
+// ContractError, TrapError, HostFunctionError, InputDecodeError,
+// OutputDecodeError or FunctionLookupError. A CallResult is returned
+// alongside the error whenever the contract was invoked.
+func (w *WasmModule) Execute(ctx context.Context, args string, callOpts ...CallOption) (*CallResult, error) {
 	// Parse the JSON string
-	var inputMap map[string]interface{}
+	var inputMap map[string]json.RawMessage
 	err := json.Unmarshal([]byte(args), &inputMap)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse input JSON: %v", err)
+		return nil, &InputDecodeError{Err: err}
 	}
 	if len(inputMap) != 1 {
-		return "", errors.New("input JSON must contain exactly one function")
+		return nil, &InputDecodeError{Err: errors.New("input JSON must contain exactly one function")}
 	}
 
-	// Extract function name and input struct
+	// Extract function name and input payload
 	var funcName string
-	var inputStruct interface{}
+	var payload json.RawMessage
 	for key, value := range inputMap {
 		funcName = key
-		inputStruct = value
+		payload = value
+	}
+
+	return w.CallContext(ctx, funcName, payload, callOpts...)
+}
+
+// Call invokes the contract function funcName with a raw JSON payload,
+// which is handed to the contract as is
+func (w *WasmModule) Call(funcName string, payload json.RawMessage, callOpts ...CallOption) (*CallResult, error) {
+	return w.CallContext(context.Background(), funcName, payload, callOpts...)
+}
+
+// CallContext is like Call, but interrupts the running contract once ctx
+// is cancelled or its deadline passes
+func (w *WasmModule) CallContext(ctx context.Context, funcName string, payload json.RawMessage, callOpts ...CallOption) (*CallResult, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := w.call(ctx, funcName, payload, callOpts...)
+		if !errors.Is(err, ErrSharedInterrupt) || len(w.hostCalls) > 0 || attempt == maxInterruptRetries {
+			return result, err
+		}
+	}
+}
+
+// call makes a single attempt at a call of CallContext
+func (w *WasmModule) call(ctx context.Context, funcName string, payload json.RawMessage, callOpts ...CallOption) (*CallResult, error) {
+	if ctx.Err() != nil {
+		return nil, contextError(ctx)
 	}
+	cfg := w.newCallConfig(callOpts)
+
+	if w.trapped {
+		if err := w.instantiate(); err != nil {
+			return nil, fmt.Errorf("failed to instantiate trapped contract anew: %w", err)
+		}
+	}
+
+	if !json.Valid(payload) {
+		return nil, &InputDecodeError{Err: fmt.Errorf("payload of %s is not valid JSON", funcName)}
+	}
+	inputJSON := []byte(payload)
 
 	// Append '' suffix to get the actual function name which is wrapped by Rust libs
 	wrapperFuncName := funcName + "_"
 
-	// Serialize the input struct to JSON
-	inputJSON, err := json.Marshal(inputStruct)
-	if err != nil {
-		return "", fmt.Errorf("failed to serialize input struct: %v", err)
+	// Retrieve the wrapper function
+	extern := w.instance.GetExport(w.store, wrapperFuncName)
+	if extern == nil {
+		return nil, &FunctionLookupError{Function: funcName, Err: ErrFunctionNotFound}
 	}
 
+	function := extern.Func()
+	if function == nil {
+		return nil, &FunctionLookupError{Function: funcName, Err: ErrNotAFunction}
+	}
+
+	// Expose ctx to host functions for the duration of the call
+	prevCtx := w.wasmCtx.BaseContext()
+	w.wasmCtx.WithBaseContext(ctx)
+	defer w.wasmCtx.WithBaseContext(prevCtx)
+
+	stopWatching := w.watchContext(ctx)
+	defer stopWatching()
+
+	// Meter the call
+	if err := w.setFuel(cfg.gasLimit); err != nil {
+		return nil, fmt.Errorf("failed to set gas limit: %v", err)
+	}
+	startFuel := fuelConsumed(w.store)
+
+	result := &CallResult{}
+	w.hostCalls = nil
+	w.hostErr = nil
+	w.wasmCtx.DrainRecords()
+	w.wasmCtx.TakeHostError()
+	startTime := time.Now()
+	defer func() {
+		w.gasUsed = fuelConsumed(w.store) - startFuel
+		result.GasUsed = w.gasUsed
+		result.Duration = time.Since(startTime)
+		result.HostCalls = w.hostCalls
+		result.Logs, result.Events = w.wasmCtx.DrainRecords()
+		if w.wasmCtx.StateStore() != nil {
+			result.StateRoot = w.callStateRoot(len(result.StateChanges) > 0)
+		}
+	}()
+
+	// Buffer state writes, which are only committed if the call succeeds
+	txn, endTxn := w.beginStateTxn(cfg)
+	defer endTxn()
+
 	// Allocate memory for input data
 	inputPtr, err := w.allocate(inputJSON)
 	if err != nil {
-		return "", fmt.Errorf("failed to allocate memory for input data: %v", err)
+		return result, w.guestError(ctx, funcName, err, cfg, startFuel)
 	}
 	defer w.deallocate(inputPtr, int32(len(inputJSON)))
 
 	// Prepare pointers for output data
 	outputPtrPtr, err := w.allocate(make([]byte, 4)) // 4 bytes for pointer
 	if err != nil {
-		return "", fmt.Errorf("failed to allocate memory for output_ptr_ptr: %v", err)
+		return result, w.guestError(ctx, funcName, err, cfg, startFuel)
 	}
 	defer w.deallocate(outputPtrPtr, 4)
 
 	outputLenPtr, err := w.allocate(make([]byte, 4)) // 4 bytes for length
 	if err != nil {
-		return "", fmt.Errorf("failed to allocate memory for output_len_ptr: %v", err)
+		return result, w.guestError(ctx, funcName, err, cfg, startFuel)
 	}
 	defer w.deallocate(outputLenPtr, 4)
 
-	// Retrieve the wrapper function
-	extern := w.instance.GetExport(w.store, wrapperFuncName)
-	if extern == nil {
-		return "", fmt.Errorf("function %s does not exist in the contract", funcName)
-	}
-
-	function := extern.Func()
-	if function == nil {
-		return "", fmt.Errorf("export %s is not a function", funcName)
-	}
-
 	// Call the wrapper function
 	ret, err := function.Call(w.store, inputPtr, len(inputJSON), outputPtrPtr, outputLenPtr)
 	if err != nil {
-		return "", fmt.Errorf("error calling WASM function: %v", err)
+		return result, w.guestError(ctx, funcName, err, cfg, startFuel)
 	}
 
 	// Check return code
 	retCode, ok := ret.(int32)
 	if !ok {
-		return "", errors.New("unexpected return type from WASM function")
+		return result, &OutputDecodeError{Function: funcName, Err: errors.New("unexpected return type from WASM function")}
 	}
+	result.ReturnCode = retCode
 
 	// Read output_ptr_ptr and output_len_ptr
 	memoryData := w.memory.UnsafeData(w.store)
 	if len(memoryData) < int(outputPtrPtr)+4 || len(memoryData) < int(outputLenPtr)+8 {
-		return "", errors.New("invalid memory access for output pointers")
+		return result, &OutputDecodeError{Function: funcName, Err: errors.New("invalid memory access for output pointers")}
 	}
 
 	outputPtr := int32(binary.LittleEndian.Uint32(memoryData[outputPtrPtr:]))
 	outputLen := int32(binary.LittleEndian.Uint64(memoryData[outputLenPtr:]))
 
 	// Validate memory bounds
-	if outputPtr < 0 || outputPtr+outputLen > int32(len(memoryData)) {
-		return "", errors.New("output data exceeds memory bounds")
+	if outputPtr < 0 || outputLen < 0 || int(outputPtr)+int(outputLen) > len(memoryData) {
+		return result, &OutputDecodeError{Function: funcName, Err: errors.New("output data exceeds memory bounds")}
 	}
 
 	// Read output data
@@ -274,64 +550,86 @@ func (w *WasmModule) CallFunction(args string) (string, error) {
 	var output interface{}
 	err = json.Unmarshal(outputData, &output)
 	if err != nil {
-		return "", fmt.Errorf("failed to deserialize output data: %v", err)
+		return result, &OutputDecodeError{Function: funcName, Err: err}
 	}
 
 	// Deallocate output data
 	err = w.deallocate(outputPtr, outputLen)
 	if err != nil {
-		return "", fmt.Errorf("failed to deallocate output data: %v", err)
+		return result, w.guestError(ctx, funcName, err, cfg, startFuel)
 	}
 
 	// Type assert output to string
 	contractOutputStr, ok := output.(string)
 	if !ok {
-		return "", fmt.Errorf("expected output of contract to be string type")
+		return result, &OutputDecodeError{Function: funcName, Err: errors.New("expected output of contract to be string type")}
 	}
+	result.Output = contractOutputStr
 
 	if retCode != 0 {
-		return "", fmt.Errorf("contract execution failed: %v", contractOutputStr)
+		return result, &ContractError{Function: funcName, Code: retCode, Message: contractOutputStr}
+	}
+
+	// The writes are discarded if the state read by the call has changed
+	// since, the error then matches state.ErrConflict
+	if txn != nil {
+		result.StateChanges, err = txn.Commit()
+		if err != nil {
+			return result, fmt.Errorf("failed to commit state of %s: %w", funcName, err)
+		}
 	}
 
-	return contractOutputStr, nil
+	return result, nil
 }
 
-func (w *WasmModule) GetSmartContractData(smartContractHash string, latest bool) (string, error) {
-	reqData := map[string]interface{}{
-		"token":  smartContractHash,
-		"latest": latest,
-	}
-	bodyJSON, err := json.Marshal(reqData)
+// CallTyped marshals req to JSON, invokes the contract function funcName
+// and decodes its output into Resp. A string Resp receives the output as
+// is, any other type is decoded from the JSON the contract returned.
+func CallTyped[Req, Resp any](ctx context.Context, w *WasmModule, funcName string, req Req, callOpts ...CallOption) (Resp, *CallResult, error) {
+	var resp Resp
+
+	payload, err := json.Marshal(req)
 	if err != nil {
-		return "", err
+		return resp, nil, &InputDecodeError{Err: fmt.Errorf("failed to serialize input struct: %v", err)}
 	}
-	url := w.nodeAddress + "/api/get-smart-contract-token-chain-data"
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(bodyJSON))
+
+	result, err := w.CallContext(ctx, funcName, payload, callOpts...)
 	if err != nil {
-		return "", err
+		return resp, result, err
 	}
-	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
+
+	if out, ok := any(&resp).(*string); ok {
+		*out = result.Output
+		return resp, result, nil
 	}
-	smartContractTokenData, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
+	if err := json.Unmarshal([]byte(result.Output), &resp); err != nil {
+		return resp, result, &OutputDecodeError{Function: funcName, Err: err}
 	}
-	var dataReply SmartContractDataReply
 
-	if err := json.Unmarshal(smartContractTokenData, &dataReply); err != nil {
+	return resp, result, nil
+}
+
+func (w *WasmModule) GetSmartContractData(smartContractHash string, latest bool) (string, error) {
+	dataReply, err := w.client().GetSmartContractData(context.Background(), rubixclient.SmartContractDataRequest{
+		Token:  smartContractHash,
+		Latest: latest,
+	})
+	if err != nil {
 		return "", err
 	}
 
-	smartContractData := dataReply.SCTDataReply
-	smartContractDataString, err := json.Marshal(smartContractData)
+	smartContractDataString, err := json.Marshal(dataReply.SCTDataReply)
 	if err != nil {
 		return "", err
 	}
 
 	return string(smartContractDataString), nil
+}
 
+// client returns the client to call the Rubix node with
+func (w *WasmModule) client() *rubixclient.Client {
+	if w.rubixClient != nil {
+		return w.rubixClient
+	}
+	return rubixclient.ForNode(w.nodeAddress)
 }
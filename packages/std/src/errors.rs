@@ -1,20 +1,55 @@
 use serde::{Deserialize, Serialize};
+use std::slice;
+
+/// Code of the error a host function returns when the contract lacks the
+/// capability to call it and the host hands errors back to the contract.
+pub const PERMISSION_DENIED: i32 = 403;
 
 #[derive(Debug, Serialize, Deserialize)]
 pub struct WasmError {
-    pub msg: String
+    pub msg: String,
+    // code is the non-zero code returned by a failing host function, 0 otherwise
+    #[serde(default)]
+    pub code: i32,
+}
+
+impl WasmError {
+    pub fn new(code: i32, msg: String) -> Self {
+        WasmError { msg, code }
+    }
 }
 
 impl From<String> for WasmError {
     fn from(msg: String) -> Self {
-        WasmError { msg }
+        WasmError { msg, code: 0 }
     }
 }
 
 impl From<&str> for WasmError {
     fn from(msg: &str) -> Self {
         WasmError {
-            msg: msg.to_string()
+            msg: msg.to_string(),
+            code: 0,
         }
     }
-}
\ No newline at end of file
+}
+
+// HostErrorPayload is written by host functions which hand an error back
+// to the contract instead of trapping
+#[derive(Deserialize)]
+struct HostErrorPayload {
+    code: i32,
+    message: String,
+}
+
+/// Builds the error of a host function call which returned the non-zero `result`,
+/// decoding the `{"code", "message"}` payload at `resp_ptr` if the host wrote one.
+pub unsafe fn host_error(result: i32, resp_ptr: *const u8, resp_len: usize) -> WasmError {
+    if !resp_ptr.is_null() {
+        let payload = slice::from_raw_parts(resp_ptr, resp_len);
+        if let Ok(host_err) = serde_json::from_slice::<HostErrorPayload>(payload) {
+            return WasmError::new(host_err.code, host_err.message);
+        }
+    }
+    WasmError::new(result, format!("Host function returned error code {}", result))
+}
@@ -3,9 +3,10 @@ use super::imports::do_mint_nft;
 use super::imports::do_transfer_nft;
 use super::imports::do_mint_ft;
 use super::imports::do_transfer_ft;
+use super::imports::emit_event;
 use std::slice;
 use std::str;
-use super::errors::WasmError;
+use super::errors::{host_error, WasmError};
 use serde::{Serialize,Deserialize};
 use serde_json;
 
@@ -36,6 +37,12 @@ pub struct TransferFt{
     pub receiver:    String,
 }
 
+#[derive(Serialize, Deserialize)]
+pub struct Event {
+    pub name:       String,
+    pub data:       String,
+}
+
 #[derive(Serialize, Deserialize)]
 pub struct MintFt {
     pub did:        String, 
@@ -67,7 +74,7 @@ pub fn call_do_api_call(url: &str) -> Result<String, WasmError> {
         );
         
         if result != 0 {
-            return Err(WasmError::from(format!("Host function returned error code {}", result)));
+            return Err(host_error(result, resp_ptr, resp_len));
         }
 
         // Ensure the response pointer is not null
@@ -106,7 +113,7 @@ pub fn call_mint_nft_api(input_data: CreateNft ) -> Result<String, WasmError> {
         );
         
         if result != 0 {
-            return Err(WasmError::from(format!("Host function returned error code {}", result)));
+            return Err(host_error(result, resp_ptr, resp_len));
         }
 
         // Ensure the response pointer is not null
@@ -144,7 +151,7 @@ pub fn call_transfer_nft_api(input_data: TransferNft) -> Result<String, WasmErro
         );
         
         if result != 0 {
-            return Err(WasmError::from(format!("Host function returned error code {}", result)));
+            return Err(host_error(result, resp_ptr, resp_len));
         }
 
         // Ensure the response pointer is not null
@@ -185,7 +192,7 @@ pub fn call_mint_ft_api(input_data: MintFt ) -> Result<String, WasmError> {
         );
         
         if result != 0 {
-            return Err(WasmError::from(format!("Host function returned error code {}", result)));
+            return Err(host_error(result, resp_ptr, resp_len));
         }
 
         // Ensure the response pointer is not null
@@ -224,7 +231,7 @@ pub fn call_transfer_ft_api(input_data: TransferFt) -> Result<String, WasmError>
         );
         
         if result != 0 {
-            return Err(WasmError::from(format!("Host function returned error code {}", result)));
+            return Err(host_error(result, resp_ptr, resp_len));
         }
 
         // Ensure the response pointer is not null
@@ -240,4 +247,31 @@ pub fn call_transfer_ft_api(input_data: TransferFt) -> Result<String, WasmError>
         }
     }
 
-}
\ No newline at end of file
+}
+
+// call_emit_event records an event, which the host reports in the result of the contract call
+pub fn call_emit_event(event: Event) -> Result<(), WasmError> {
+    unsafe {
+        // Convert the event to bytes
+        let input_bytes = serde_json::to_string(&event).unwrap().into_bytes();
+        let input_ptr = input_bytes.as_ptr();
+        let input_len = input_bytes.len();
+
+        // emit_event has no response, but the host function signature requires the pointers
+        let mut resp_ptr: *const u8 = std::ptr::null();
+        let mut resp_len: usize = 0;
+
+        let result = emit_event(
+            input_ptr,
+            input_len,
+            &mut resp_ptr,
+            &mut resp_len,
+        );
+
+        if result != 0 {
+            return Err(WasmError::from(format!("Host function returned error code {}", result)));
+        }
+
+        Ok(())
+    }
+}
@@ -32,4 +32,53 @@ extern "C" {
         resp_ptr_ptr: *mut *const u8,
         resp_len_ptr: *mut usize,
     ) -> i32;
+    // emit_event records an event in the result of the contract call
+    pub fn emit_event(
+        inputdata_ptr: *const u8,
+        inputdata_len: usize,
+        resp_ptr_ptr: *mut *const u8,
+        resp_len_ptr: *mut usize,
+    ) -> i32;
+    // state_get reads a key of the contract state
+    pub fn state_get(
+        inputdata_ptr: *const u8,
+        inputdata_len: usize,
+        resp_ptr_ptr: *mut *const u8,
+        resp_len_ptr: *mut usize,
+    ) -> i32;
+    // state_set writes a key of the contract state
+    pub fn state_set(
+        inputdata_ptr: *const u8,
+        inputdata_len: usize,
+        resp_ptr_ptr: *mut *const u8,
+        resp_len_ptr: *mut usize,
+    ) -> i32;
+    // state_delete removes a key of the contract state
+    pub fn state_delete(
+        inputdata_ptr: *const u8,
+        inputdata_len: usize,
+        resp_ptr_ptr: *mut *const u8,
+        resp_len_ptr: *mut usize,
+    ) -> i32;
+    // state_has checks whether a key of the contract state is set
+    pub fn state_has(
+        inputdata_ptr: *const u8,
+        inputdata_len: usize,
+        resp_ptr_ptr: *mut *const u8,
+        resp_len_ptr: *mut usize,
+    ) -> i32;
+    // state_iter_prefix reads a page of the keys starting with a prefix
+    pub fn state_iter_prefix(
+        inputdata_ptr: *const u8,
+        inputdata_len: usize,
+        resp_ptr_ptr: *mut *const u8,
+        resp_len_ptr: *mut usize,
+    ) -> i32;
+    // state_range reads a page of the keys in a range
+    pub fn state_range(
+        inputdata_ptr: *const u8,
+        inputdata_len: usize,
+        resp_ptr_ptr: *mut *const u8,
+        resp_len_ptr: *mut usize,
+    ) -> i32;
 }
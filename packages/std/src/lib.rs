@@ -2,6 +2,7 @@ pub mod memory;
 pub mod imports;
 pub mod helpers;
 pub mod errors;
+pub mod state;
 
 pub use rubixwasm_derive::contract_fn;
 
@@ -10,3 +11,8 @@ pub use helpers::call_mint_nft_api;
 pub use helpers::call_transfer_nft_api;
 pub use helpers::call_mint_ft_api;
 pub use helpers::call_transfer_ft_api;
+pub use helpers::call_emit_event;
+pub use state::{
+    state_delete, state_get, state_has, state_iter_prefix, state_range, state_set, MigrationInput, StateEntry,
+    StatePage,
+};
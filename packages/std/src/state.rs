@@ -0,0 +1,173 @@
+use super::errors::{host_error, WasmError};
+use super::imports;
+use serde::{Deserialize, Serialize};
+use std::collections::HashMap;
+use std::slice;
+use std::str;
+
+// Code of the error state_get returns for a missing key
+const KEY_NOT_FOUND: i32 = 404;
+
+/// Code of the error `state_set` returns when the write would exceed the
+/// state quota of the contract.
+pub const QUOTA_EXCEEDED: i32 = 507;
+
+type HostFn = unsafe extern "C" fn(*const u8, usize, *mut *const u8, *mut usize) -> i32;
+
+#[derive(Serialize)]
+struct KeyReq<'a> {
+    key: &'a str,
+}
+
+#[derive(Serialize)]
+struct SetReq<'a> {
+    key: &'a str,
+    value: &'a str,
+}
+
+#[derive(Serialize)]
+struct IterPrefixReq<'a> {
+    prefix: &'a str,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    cursor: Option<&'a str>,
+    limit: u32,
+}
+
+#[derive(Serialize)]
+struct RangeReq<'a> {
+    start: &'a str,
+    #[serde(skip_serializing_if = "str::is_empty")]
+    end: &'a str,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    cursor: Option<&'a str>,
+    limit: u32,
+}
+
+/// A key of the contract state with its value.
+#[derive(Deserialize, Debug, Clone)]
+pub struct StateEntry {
+    pub key: String,
+    pub value: String,
+}
+
+/// A page of state entries in ascending key order. `next_cursor` is `None`
+/// once the iteration is complete; otherwise it is passed as the cursor of
+/// the next request.
+#[derive(Deserialize, Debug, Clone)]
+pub struct StatePage {
+    pub entries: Vec<StateEntry>,
+    #[serde(default)]
+    pub next_cursor: Option<String>,
+}
+
+/// The input of a contract's `migrate` function. The function returns the
+/// migrated entries, encoded with `serde_json::to_string`, and the host
+/// swaps them in as the new contract state.
+#[derive(Deserialize, Debug, Clone)]
+pub struct MigrationInput {
+    pub contract_id: String,
+    pub entries: HashMap<String, String>,
+}
+
+// call_state_fn passes the JSON encoded input to a state host function and returns its response
+fn call_state_fn<T: Serialize>(host_fn: HostFn, input: &T) -> Result<String, WasmError> {
+    let input_bytes = serde_json::to_vec(input)
+        .map_err(|e| WasmError::from(format!("failed to encode state request: {}", e)))?;
+
+    unsafe {
+        let mut resp_ptr: *const u8 = std::ptr::null();
+        let mut resp_len: usize = 0;
+
+        let result = host_fn(
+            input_bytes.as_ptr(),
+            input_bytes.len(),
+            &mut resp_ptr,
+            &mut resp_len,
+        );
+
+        if result != 0 {
+            return Err(host_error(result, resp_ptr, resp_len));
+        }
+
+        if resp_ptr.is_null() || resp_len == 0 {
+            return Ok(String::new());
+        }
+
+        let response_slice = slice::from_raw_parts(resp_ptr, resp_len);
+        match str::from_utf8(response_slice) {
+            Ok(s) => Ok(s.to_string()),
+            Err(_) => Err(WasmError::from("Invalid UTF-8 response".to_string())),
+        }
+    }
+}
+
+/// Returns the value stored under `key` in the contract state, or `None` if it is not set.
+pub fn state_get(key: &str) -> Result<Option<String>, WasmError> {
+    match call_state_fn(imports::state_get, &KeyReq { key }) {
+        Ok(value) => Ok(Some(value)),
+        Err(e) if e.code == KEY_NOT_FOUND => Ok(None),
+        Err(e) => Err(e),
+    }
+}
+
+/// Stores `value` under `key` in the contract state. Fails with code
+/// `QUOTA_EXCEEDED` if the contract state would outgrow its quota.
+pub fn state_set(key: &str, value: &str) -> Result<(), WasmError> {
+    call_state_fn(imports::state_set, &SetReq { key, value }).map(|_| ())
+}
+
+/// Removes `key` from the contract state.
+pub fn state_delete(key: &str) -> Result<(), WasmError> {
+    call_state_fn(imports::state_delete, &KeyReq { key }).map(|_| ())
+}
+
+/// Reports whether `key` is set in the contract state.
+pub fn state_has(key: &str) -> Result<bool, WasmError> {
+    let response = call_state_fn(imports::state_has, &KeyReq { key })?;
+    serde_json::from_str(&response)
+        .map_err(|e| WasmError::from(format!("invalid state_has response: {}", e)))
+}
+
+/// Returns a page of the keys starting with `prefix`, resuming at `cursor`.
+/// A `limit` of 0 uses the host's default page size.
+pub fn state_iter_prefix(
+    prefix: &str,
+    cursor: Option<&str>,
+    limit: u32,
+) -> Result<StatePage, WasmError> {
+    let response = call_state_fn(
+        imports::state_iter_prefix,
+        &IterPrefixReq {
+            prefix,
+            cursor,
+            limit,
+        },
+    )?;
+    decode_page(&response)
+}
+
+/// Returns a page of the keys in `[start, end)`, resuming at `cursor`. An
+/// empty `end` means no upper bound. A `limit` of 0 uses the host's default
+/// page size.
+pub fn state_range(
+    start: &str,
+    end: &str,
+    cursor: Option<&str>,
+    limit: u32,
+) -> Result<StatePage, WasmError> {
+    let response = call_state_fn(
+        imports::state_range,
+        &RangeReq {
+            start,
+            end,
+            cursor,
+            limit,
+        },
+    )?;
+    decode_page(&response)
+}
+
+fn decode_page(response: &str) -> Result<StatePage, WasmError> {
+    serde_json::from_str(response)
+        .map_err(|e| WasmError::from(format!("invalid state page: {}", e)))
+}